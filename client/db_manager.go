@@ -2,6 +2,7 @@ package client
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/itea-tgl/itea-go/constant"
@@ -19,10 +20,10 @@ const (
 )
 
 type DatabaseConf struct {
-	Driver 			string
-	Ip 				string
-	Port 			int
-	Database 		string
+	Driver 			string	`schema:"required"`
+	Ip 				string	`schema:"required"`
+	Port 			int		`schema:"min=1,max=65535"`
+	Database 		string	`schema:"required"`
 	Username 		string
 	Password 		string
 	Charset 		string
@@ -31,24 +32,29 @@ type DatabaseConf struct {
 	ConnMaxLift 	int
 }
 
+func init() {
+	system.RegisterSchema(fmt.Sprintf("%s.%s.*", constant.DATABASE_KEY, CONNECTION_KEY), DatabaseConf{})
+}
+
 type DbManager struct {
 	databases 		map[string]*DatabaseConf
 	connections 	map[string]*sql.DB
 	mutex 			*sync.Mutex
 }
 
-func (dm *DbManager) Construct() {
-	if c := system.Conf.GetStructMap(fmt.Sprintf("%s.%s", constant.DATABASE_KEY, CONNECTION_KEY), DatabaseConf{}); c != nil {
-		dbconf := make(map[string]*DatabaseConf)
-		for db, dc := range c {
-			dbconf[db] = dc.(*DatabaseConf)
-		}
-		dm.databases = dbconf
-	} else {
-		panic("Can not find database config of connections!")
+func (dm *DbManager) Construct() error {
+	c := system.Conf.GetStructMap(fmt.Sprintf("%s.%s", constant.DATABASE_KEY, CONNECTION_KEY), DatabaseConf{})
+	if c == nil {
+		return errors.New("can not find database config of connections")
 	}
+	dbconf := make(map[string]*DatabaseConf)
+	for db, dc := range c {
+		dbconf[db] = dc.(*DatabaseConf)
+	}
+	dm.databases = dbconf
 	dm.connections = make(map[string]*sql.DB)
 	dm.mutex = new(sync.Mutex)
+	return nil
 }
 
 func (dm *DbManager) GetDbConnection(name string) (db *sql.DB) {
@@ -99,3 +105,21 @@ func (dm *DbManager) dataSource(dbconfig *DatabaseConf) string {
 	return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=%s",
 		dbconfig.Username, dbconfig.Password, dbconfig.Ip, dbconfig.Port, dbconfig.Database, dbconfig.Charset)
 }
+
+//Close all connections
+func (dm *DbManager) Close() error {
+	dm.mutex.Lock()
+	defer dm.mutex.Unlock()
+	var err error
+	for name, db := range dm.connections {
+		if db == nil {
+			continue
+		}
+		if e := db.Close(); e != nil {
+			ilog.Error("database [", name, "] close fail : ", e)
+			err = e
+		}
+	}
+	dm.connections = make(map[string]*sql.DB)
+	return err
+}
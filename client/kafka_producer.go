@@ -42,4 +42,9 @@ func (sp *KafkaSyncProducer) Send(topic string, key string, value string) error
 		ilog.Info(fmt.Sprintf("【Kafka Send】 pid: %v, offset: %v", pid, offset))
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+//Close producer
+func (sp *KafkaSyncProducer) Close() error {
+	return sp.client.Close()
+}
@@ -2,6 +2,7 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/go-redis/redis"
 	"github.com/itea-tgl/itea-go/constant"
@@ -26,8 +27,8 @@ const (
 
 type RedisConf struct {
 	Host 			string
-	Port 			int
-	Database 		int
+	Port 			int		`schema:"min=1,max=65535"`
+	Database 		int		`schema:"min=0"`
 	Password 		string
 	MaxIdle 		int
 	MaxActive 		int
@@ -36,20 +37,24 @@ type RedisConf struct {
 	IdleCheck 		int
 }
 
+func init() {
+	system.RegisterSchema(fmt.Sprintf("%s.%s", constant.DATABASE_KEY, REDIS_KEY), RedisConf{})
+}
+
 type Redis struct {
 	pool 			*redis.Client
 	Ctx 			context.Context
 	debug 			bool
 }
 
-func (p *Redis) Construct() {
+func (p *Redis) Construct() error {
 	if d, ok := p.Ctx.Value(constant.DEBUG).(bool); ok {
 		p.debug = d
 	}
 
 	c := system.Conf.GetStruct(fmt.Sprintf("%s.%s", constant.DATABASE_KEY, REDIS_KEY), RedisConf{})
 	if c == nil {
-		panic("Can not find database config of redis!")
+		return errors.New("can not find database config of redis")
 	}
 
 	p.pool = redis.NewClient(p.initOpt(c.(*RedisConf)))
@@ -61,6 +66,15 @@ func (p *Redis) Construct() {
 	//		fmt.Printf("PoolStats, TotalConns: %d, FreeConns: %d\n", p.pool.PoolStats().TotalConns, p.pool.PoolStats().IdleConns)
 	//	}
 	//}()
+	return nil
+}
+
+//Close redis pool
+func (p *Redis) Close() error {
+	if p.pool == nil {
+		return nil
+	}
+	return p.pool.Close()
 }
 
 func (p *Redis) initOpt(conf *RedisConf) *redis.Options {
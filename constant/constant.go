@@ -8,4 +8,14 @@ const (
 	DEFAULT_ENV		= "dev"
 	IMPORT_KEY		= "import"
 	DATABASE_KEY	= "database"
+	SCHEMA_KEY		= "schema"	//Key of schema file validating config
+	DESTROY_TIMEOUT_KEY	= "destroy_timeout"
+	EAGER_KEY		= "eager"
+	WATCH_KEY		= "watch"	//Key of reloading config when its files change
+	LOCAL_PROFILE	= "local"	//Profile of local config overriding the others
+	ENV_PREFIX		= "ITEA_"	//Prefix of environment variables overriding config
+	ENV_SEPARATOR	= "__"		//Separator of keys in environment variables overriding config
+	CONFIG_KEY_ENV	= "ITEAGO_CONFIG_KEY"		//Environment variable of key decrypting config, raw or like base64:{KEY}, ITEAGO_CONFIG_KEY_{ID} for key of ID
+	CONFIG_KEY_ID_ENV	= "ITEAGO_CONFIG_KEY_ID"	//Environment variable of ID of key encrypting config
+	CONFIG_KEY_FILE_ENV	= "ITEAGO_CONFIG_KEY_FILE"	//Environment variable of key file, with lines like {ID}={KEY}, or base64:{KEY} for the default key
 )
@@ -2,13 +2,32 @@ package bean
 
 import "reflect"
 
+const (
+	SINGLETON 		= "singleton"	//One instance for the container
+	PROTOTYPE 		= "prototype"	//New instance for every lookup
+	PROCESS 		= "process"		//One instance for each process
+	REQUEST 		= "request"		//One instance for each http request or kafka message
+)
+
+//Beans registered so far, seen by conditions
+type Registry interface {
+	BeansByName(name string) *Bean
+	BeansByType(t reflect.Type) []*Bean
+}
+
+//Condition of activating bean b
+type Condition func(b *Bean, r Registry) bool
+
 type Bean struct {
 	Name 			string
 	Scope 			string
 	Abstract 		interface{}
 	Concrete 		interface{}
+	Order 			int				//Position in wired slices, lower first
+	Conditions 		[]Condition		//Bean is active only when all conditions hold
 	abstractType 	reflect.Type
 	concreteType 	reflect.Type
+	factory 		reflect.Value
 }
 
 func (b *Bean)SetAbstractType(t reflect.Type) {
@@ -26,3 +45,56 @@ func (b *Bean)GetAbstractType() reflect.Type {
 func (b *Bean)GetConcreteType() reflect.Type{
 	return b.concreteType
 }
+
+func (b *Bean)SetFactory(f reflect.Value) {
+	b.factory = f
+}
+
+//Get function creating the bean, invalid if the bean is created from its concrete type
+func (b *Bean)GetFactory() reflect.Value {
+	return b.factory
+}
+
+//Type of instance of the bean
+func (b *Bean)InstanceType() reflect.Type {
+	if b.factory.IsValid() {
+		return b.factory.Type().Out(0)
+	}
+	return reflect.PtrTo(b.concreteType)
+}
+
+//Get type of method of instance, without receiver
+func (b *Bean)Method(name string) (reflect.Type, bool) {
+	t := b.InstanceType()
+	m, ok := t.MethodByName(name)
+	if !ok {
+		return nil, false
+	}
+	if t.Kind() == reflect.Interface {
+		return m.Type, true
+	}
+	in := make([]reflect.Type, 0, m.Type.NumIn())
+	for i := 1; i < m.Type.NumIn(); i++ {
+		in = append(in, m.Type.In(i))
+	}
+	out := make([]reflect.Type, 0, m.Type.NumOut())
+	for i := 0; i < m.Type.NumOut(); i++ {
+		out = append(out, m.Type.Out(i))
+	}
+	return reflect.FuncOf(in, out, m.Type.IsVariadic()), true
+}
+
+//Whether the bean is created for each request
+func (b *Bean)PerRequest() bool {
+	return b.Scope == REQUEST || b.Scope == PROTOTYPE
+}
+
+//Whether all conditions of the bean hold
+func (b *Bean)Active(r Registry) bool {
+	for _, c := range b.Conditions {
+		if !c(b, r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,78 @@
+package ioc
+
+import (
+	"github.com/itea-tgl/itea-go/ioc/bean"
+	"github.com/itea-tgl/itea-go/system"
+	"reflect"
+	"strings"
+)
+
+//Bean of class active only when all conditions hold, class is a struct or a factory like in Register
+func When(class interface{}, conditions ...bean.Condition) *bean.Bean {
+	return &bean.Bean{
+		Concrete: class,
+		Conditions: conditions,
+	}
+}
+
+//Active when system.Env is one of envs
+func OnEnv(envs ...string) bean.Condition {
+	return func(b *bean.Bean, r bean.Registry) bool {
+		for _, env := range envs {
+			if strings.EqualFold(system.Env, env) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+//Active when config key exists
+func OnConfig(key string) bean.Condition {
+	return func(b *bean.Bean, r bean.Registry) bool {
+		return system.Conf.Exists(key)
+	}
+}
+
+//Active when config key equals v, config value is converted to type of v before comparing
+func OnConfigValue(key string, v interface{}) bean.Condition {
+	return func(b *bean.Bean, r bean.Registry) bool {
+		if v == nil || !system.Conf.Exists(key) {
+			return false
+		}
+		out := reflect.New(reflect.TypeOf(v))
+		if err := system.Conf.Decode(key, out.Interface()); err != nil {
+			return false
+		}
+		return reflect.DeepEqual(out.Elem().Interface(), v)
+	}
+}
+
+//Active when no bean of classes is registered before, the abstract type of the bean itself by default.
+//Register the bean after the beans it stands in for
+func OnMissingBean(classes ...interface{}) bean.Condition {
+	return func(b *bean.Bean, r bean.Registry) bool {
+		types := []reflect.Type{b.GetAbstractType()}
+		if len(classes) > 0 {
+			types = types[:0]
+			for _, c := range classes {
+				types = append(types, classType(c))
+			}
+		}
+		for _, t := range types {
+			if len(r.BeansByType(t)) > 0 {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+//Type given as class, interface is given by a nil pointer like (*IHandler)(nil)
+func classType(class interface{}) reflect.Type {
+	t := reflect.TypeOf(class)
+	if t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Interface {
+		return t.Elem()
+	}
+	return t
+}
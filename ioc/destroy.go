@@ -0,0 +1,66 @@
+package ioc
+
+import (
+	"fmt"
+	"github.com/itea-tgl/itea-go/ilog"
+	"github.com/itea-tgl/itea-go/ioc/bean"
+	"io"
+	"reflect"
+	"time"
+)
+
+const DESTROY_TIMEOUT = 5 * time.Second
+
+//Destroy singletons in reverse order of creation, each bean is waited for timeout at most.
+//Bean is destroyed by its method `Destroy()` or `Destroy() error`, or by `Close() error` of io.Closer
+func (ioc *Ioc) Destroy(timeout time.Duration) {
+	order, ins := ioc.singletons.drain()
+	destroy(order, ins, timeout)
+}
+
+func destroy(order []*bean.Bean, ins map[*bean.Bean]interface{}, timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = DESTROY_TIMEOUT
+	}
+	for i := len(order) - 1; i >= 0; i-- {
+		b := order[i]
+		if err := destroyInstance(ins[b], timeout); err != nil {
+			ilog.Error(fmt.Sprintf("destroy bean [%s] error : %s", b.Name, err))
+		}
+	}
+}
+
+func destroyInstance(ins interface{}, timeout time.Duration) error {
+	var hook func() error
+	if m := reflect.ValueOf(ins).MethodByName(DESTROY_FUNC); m.IsValid() && m.Type().NumIn() == 0 {
+		hook = func() error {
+			if res := m.Call(nil); len(res) > 0 {
+				if err, ok := res[0].Interface().(error); ok {
+					return err
+				}
+			}
+			return nil
+		}
+	} else if c, ok := ins.(io.Closer); ok {
+		hook = c.Close
+	} else {
+		return nil
+	}
+
+	ch := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				ch <- fmt.Errorf("panic : %v", r)
+			}
+		}()
+		ch <- hook()
+	}()
+
+	select {
+	case err := <-ch:
+		return err
+	case <-time.After(timeout):
+		return fmt.Errorf("timeout after %s", timeout)
+	}
+}
@@ -0,0 +1,49 @@
+package ioc
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+//Error returned when the bean asked for is not registed
+var ErrNotRegisted = errors.New("bean is not registed")
+
+//Error of creating bean, with the path resolving it
+type BeanError struct {
+	Name 		string	//Name of bean
+	Path 		string	//Resolution path, ended with the bean
+	Hook 		string	//Construct, Init, PostProcess, factory, or Build for a panic out of hooks
+	Err 		error
+}
+
+func (be *BeanError) Error() string {
+	return fmt.Sprintf("%s of bean [%s] fail : %s", be.Hook, be.Path, be.Err)
+}
+
+func (be *BeanError) Unwrap() error {
+	return be.Err
+}
+
+func beanError(p path, hook string, err error) error {
+	return &BeanError{
+		Name: p[len(p)-1].bean.Name,
+		Path: p.String(),
+		Hook: hook,
+		Err: err,
+	}
+}
+
+//Call hook of the bean on top of p, a returned error or a panic is wrapped as BeanError
+func callHook(p path, hook string, m reflect.Value, args []reflect.Value) (res []reflect.Value, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = beanError(p, hook, fmt.Errorf("panic : %v", r))
+		}
+	}()
+	res = m.Call(args)
+	if n := len(res); n > 0 && res[n-1].Type() == errorType && !res[n-1].IsNil() {
+		return nil, beanError(p, hook, res[n-1].Interface().(error))
+	}
+	return res, nil
+}
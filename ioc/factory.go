@@ -0,0 +1,69 @@
+package ioc
+
+import (
+	"errors"
+	"fmt"
+	"github.com/itea-tgl/itea-go/ioc/bean"
+	"reflect"
+)
+
+var errorType = reflect.TypeOf(new(error)).Elem()
+
+//Check function used as factory, it should be like `func(deps...) (*T, error)` or `func(deps...) I`.
+//Returns type of bean it creates
+func factoryType(ft reflect.Type) (reflect.Type, error) {
+	if ft.IsVariadic() {
+		return nil, errors.New("factory can not be variadic")
+	}
+	if n := ft.NumOut(); n == 0 || n > 2 || (n == 2 && ft.Out(1) != errorType) {
+		return nil, errors.New("factory should return (bean) or (bean, error)")
+	}
+	rt := ft.Out(0)
+	switch {
+	case rt.Kind() == reflect.Ptr && rt.Elem().Kind() == reflect.Struct:
+		return rt.Elem(), nil
+	case rt.Kind() == reflect.Interface:
+		return rt, nil
+	default:
+		return nil, fmt.Errorf("factory should return pointer of struct or interface, not %s", rt.String())
+	}
+}
+
+//Param i of factory as a wired field
+func paramOf(ft reflect.Type, i int) (*wiredField, bool) {
+	return wiredOf(reflect.StructField{
+		Name: fmt.Sprintf("param %d", i),
+		Type: ft.In(i),
+		Tag: reflect.StructTag(fmt.Sprintf(`%s:"true"`, WIRED_TAG)),
+	})
+}
+
+//Create instance by factory of bean on top of path p, params of factory are resolved like wired fields
+func (ioc *Ioc) buildByFactory(p path, s *scope, b *bean.Bean) (interface{}, error) {
+	f := b.GetFactory()
+	ft := f.Type()
+	args := make([]reflect.Value, ft.NumIn())
+	for i := range args {
+		wf, ok := paramOf(ft, i)
+		if !ok {
+			return nil, fmt.Errorf("param %d(%s) of factory of [%s] can not be wired", i, ft.In(i).String(), p)
+		}
+		v, err := ioc.wiredValue(p, s, wf)
+		if err != nil {
+			return nil, err
+		}
+		if !v.IsValid() {
+			return nil, fmt.Errorf("param %d(%s) of factory of [%s] is not registed", i, ft.In(i).String(), p)
+		}
+		args[i] = v
+	}
+
+	res, err := callHook(p, "factory", f, args)
+	if err != nil {
+		return nil, err
+	}
+	if res[0].IsNil() {
+		return nil, beanError(p, "factory", errors.New("returns nil"))
+	}
+	return res[0].Interface(), nil
+}
@@ -0,0 +1,132 @@
+package ioc
+
+import (
+	"fmt"
+	"github.com/itea-tgl/itea-go/ioc/bean"
+	"github.com/itea-tgl/itea-go/ioc/iface"
+	"reflect"
+)
+
+//Type of T
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+//Get instance of type T, T is a pointer of struct, a struct or an interface
+func Get[T any](c iface.IIoc) (T, error) {
+	t := typeOf[T]()
+	bt := t
+	if t.Kind() == reflect.Ptr {
+		bt = t.Elem()
+	}
+	ins, err := c.InsByTypeE(bt)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return as[T](ins, bt.String())
+}
+
+//Get instance of bean of name as T
+func GetNamed[T any](c iface.IIoc, name string) (T, error) {
+	ins, err := c.InsByNameE(name)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return as[T](ins, name)
+}
+
+//Get instance of type T, panics if it can not be got
+func MustGet[T any](c iface.IIoc) T {
+	v, err := Get[T](c)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+//Get instance of bean of name as T, panics if it can not be got
+func MustGetNamed[T any](c iface.IIoc, name string) T {
+	v, err := GetNamed[T](c, name)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+//Convert instance of bean to T, struct T gets a copy of the instance
+func as[T any](ins interface{}, bean string) (T, error) {
+	if v, ok := ins.(T); ok {
+		return v, nil
+	}
+	var zero T
+	t := typeOf[T]()
+	if v := reflect.ValueOf(ins); v.Kind() == reflect.Ptr && v.Elem().Type() == t {
+		return v.Elem().Interface().(T), nil
+	}
+	return zero, fmt.Errorf("bean [%s] is %T, not %s", bean, ins, t.String())
+}
+
+//Bean of struct T of scope, named after T.
+//It is registered by RegisterBean of Itea or RegisterBeans of container
+func TypeBean[T any](scope string) (*bean.Bean, error) {
+	t := typeOf[T]()
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("can not register %s, it should be struct", t.String())
+	}
+	return checkBean(&bean.Bean{
+		Scope: scope,
+		Concrete: reflect.New(t).Elem().Interface(),
+	})
+}
+
+//Bean of class of scope implementing interface I, named after I.
+//Class is a struct or a factory like in Register, the bean is registered like the one of TypeBean
+func BeanAs[I any](class interface{}, scope string) (*bean.Bean, error) {
+	t := typeOf[I]()
+	if t.Kind() != reflect.Interface {
+		return nil, fmt.Errorf("can not register as %s, it should be interface", t.String())
+	}
+	return checkBean(&bean.Bean{
+		Scope: scope,
+		Abstract: (*I)(nil),
+		Concrete: class,
+	})
+}
+
+//Register struct T as bean of scope, named after T
+func RegisterType[T any](c *Ioc, scope string) error {
+	b, err := TypeBean[T](scope)
+	if err != nil {
+		return err
+	}
+	c.RegisterBeans([]*bean.Bean{b})
+	return nil
+}
+
+//Register class as bean of scope implementing interface I, named after I.
+//Class is a struct or a factory like in Register
+func RegisterAs[I any](c *Ioc, class interface{}, scope string) error {
+	b, err := BeanAs[I](class, scope)
+	if err != nil {
+		return err
+	}
+	c.RegisterBeans([]*bean.Bean{b})
+	return nil
+}
+
+//Resolve types of bean as registering does, checking the instance implements its abstract interface.
+//Registering it again later keeps the same types
+func checkBean(b *bean.Bean) (_ *bean.Bean, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	NewRegister().RegisterBeans([]*bean.Bean{b})
+	if at := b.GetAbstractType(); at.Kind() == reflect.Interface && !b.InstanceType().Implements(at) {
+		return nil, fmt.Errorf("bean [%s] of %s does not implement %s", b.Name, b.InstanceType().String(), at.String())
+	}
+	return b, nil
+}
@@ -0,0 +1,164 @@
+package ioc
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/itea-tgl/itea-go/ioc/bean"
+	"reflect"
+	"strings"
+)
+
+const (
+	GRAPH_JSON 		= "json"
+	GRAPH_DOT 		= "dot"
+)
+
+//Beans of the container and their dependencies
+type Graph struct {
+	Beans 			[]*Node		`json:"beans"`
+}
+
+//Bean in graph
+type Node struct {
+	Name 			string		`json:"name"`
+	Abstract 		string		`json:"abstract"`
+	Concrete 		string		`json:"concrete"`
+	Scope 			string		`json:"scope"`
+	Factory 		bool		`json:"factory"`
+	Instantiated 	bool		`json:"instantiated"`
+	Wired 			[]*Edge		`json:"wired,omitempty"`
+	Values 			[]string	`json:"values,omitempty"`	//Config keys injected by `value` tag
+}
+
+//Wired field or factory param, pointing to the bean injected into it
+type Edge struct {
+	Field 			string		`json:"field"`
+	Bean 			string		`json:"bean,omitempty"`
+	Error 			string		`json:"error,omitempty"`	//Why the field can not be wired
+}
+
+//Get graph of all beans, beans are not created
+func (ioc *Ioc) Graph() *Graph {
+	g := &Graph{}
+	seen := make(map[*bean.Bean]bool)
+	//Wiring by type may add beans of unregistered structs, walk until no bean is added
+	for more := true; more; {
+		more = false
+		for _, b := range ioc.beans() {
+			if seen[b] {
+				continue
+			}
+			seen[b], more = true, true
+			g.Beans = append(g.Beans, ioc.node(b))
+		}
+	}
+	return g
+}
+
+func (ioc *Ioc) node(b *bean.Bean) *Node {
+	_, ins := ioc.singletons.get(b)
+	n := &Node{
+		Name: b.Name,
+		Abstract: typeName(b.GetAbstractType()),
+		Concrete: typeName(b.GetConcreteType()),
+		Scope: b.Scope,
+		Factory: b.GetFactory().IsValid(),
+		Instantiated: ins,
+	}
+
+	p, _ := path(nil).push(b, false)
+	if f := b.GetFactory(); f.IsValid() {
+		ft := f.Type()
+		for i := 0; i < ft.NumIn(); i++ {
+			if wf, ok := paramOf(ft, i); ok {
+				n.Wired = append(n.Wired, ioc.edges(p, wf)...)
+			}
+		}
+		return n
+	}
+
+	t := b.GetConcreteType()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if wf, ok := wiredOf(sf); ok {
+			n.Wired = append(n.Wired, ioc.edges(p, wf)...)
+		}
+		if tag := sf.Tag.Get(VALUE_TAG); !strings.EqualFold(tag, "") {
+			n.Values = append(n.Values, parseValueTag(tag).key)
+		}
+	}
+	return n
+}
+
+//Edges of wired field, one for each bean of a wired slice
+func (ioc *Ioc) edges(p path, wf *wiredField) []*Edge {
+	if wf.slice {
+		var edges []*Edge
+		for _, dep := range ioc.wiredBeans(wf) {
+			edges = append(edges, &Edge{Field: wf.field.Name, Bean: dep.Name})
+		}
+		return edges
+	}
+	e := &Edge{Field: wf.field.Name}
+	dep, err := ioc.wiredBean(p, wf)
+	switch {
+	case err != nil:
+		e.Error = err.Error()
+	case dep == nil:
+		e.Error = "not registed"
+	default:
+		e.Bean = dep.Name
+	}
+	return []*Edge{e}
+}
+
+func typeName(t reflect.Type) string {
+	if t == nil {
+		return ""
+	}
+	return t.String()
+}
+
+//Export graph as format, json or dot
+func (g *Graph) Export(format string) ([]byte, error) {
+	switch strings.ToLower(format) {
+	case GRAPH_JSON:
+		return g.JSON()
+	case GRAPH_DOT:
+		return []byte(g.Dot()), nil
+	default:
+		return nil, fmt.Errorf("graph format [%s] is not supported, use %s or %s", format, GRAPH_JSON, GRAPH_DOT)
+	}
+}
+
+//Export graph as json
+func (g *Graph) JSON() ([]byte, error) {
+	return json.MarshalIndent(g, "", "  ")
+}
+
+//Export graph as graphviz dot, instantiated beans are drawn bold and unresolved fields red
+func (g *Graph) Dot() string {
+	var sb strings.Builder
+	sb.WriteString("digraph itea {\n")
+	sb.WriteString("\tnode [shape=box];\n")
+	for _, n := range g.Beans {
+		style := "solid"
+		if n.Instantiated {
+			style = "bold"
+		}
+		fmt.Fprintf(&sb, "\t%q [label=%q, style=%s];\n", n.Name, fmt.Sprintf("%s\n%s\n%s", n.Name, n.Concrete, n.Scope), style)
+	}
+	for _, n := range g.Beans {
+		for _, e := range n.Wired {
+			if strings.EqualFold(e.Bean, "") {
+				missing := fmt.Sprintf("%s.%s", n.Name, e.Field)
+				fmt.Fprintf(&sb, "\t%q [label=%q, color=red];\n", missing, e.Error)
+				fmt.Fprintf(&sb, "\t%q -> %q [label=%q, color=red];\n", n.Name, missing, e.Field)
+				continue
+			}
+			fmt.Fprintf(&sb, "\t%q -> %q [label=%q];\n", n.Name, e.Bean, e.Field)
+		}
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
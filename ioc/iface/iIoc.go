@@ -1,6 +1,7 @@
 package iface
 
 import (
+	"context"
 	"github.com/itea-tgl/itea-go/ioc/bean"
 	"reflect"
 )
@@ -8,5 +9,10 @@ import (
 type IIoc interface {
 	InsByName(name string) interface{}
 	InsByType(t reflect.Type) interface{}
+	InsByNameE(name string) (interface{}, error)
+	InsByTypeE(t reflect.Type) (interface{}, error)
+	InsByNameCtx(ctx context.Context, name string) interface{}
+	InsByTypeCtx(ctx context.Context, t reflect.Type) interface{}
+	BeginRequest(ctx context.Context) (context.Context, func())
 	BeansByName(name string) *bean.Bean
-}
\ No newline at end of file
+}
@@ -0,0 +1,9 @@
+package iface
+
+import "github.com/itea-tgl/itea-go/ioc/bean"
+
+//Hook called by the container for every bean after its Init.
+//It returns the instance to keep, ins itself or a decorator of it, or an error rejecting the bean
+type IPostProcessor interface {
+	PostProcess(b *bean.Bean, ins interface{}) (interface{}, error)
+}
@@ -2,12 +2,14 @@ package ioc
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/itea-tgl/itea-go/ilog"
 	"github.com/itea-tgl/itea-go/ioc/bean"
 	"github.com/itea-tgl/itea-go/process"
 	"github.com/itea-tgl/itea-go/system"
 	"reflect"
+	"sort"
 	"strings"
 	"sync"
 )
@@ -19,6 +21,11 @@ const (
 	CONSTRUCT_FUNC 	= "Construct"
 	INIT_FUNC 		= "Init"
 	EXEC_FUNC 		= "Execute"
+	DESTROY_FUNC 	= "Destroy"
+	VALIDATE_FUNC 	= "Validate"
+	ORDER_FUNC 		= "Order"
+	WIRED_TAG 		= "wired"
+	VALUE_TAG 		= "value"
 ) 
 
 type Ioc struct {
@@ -26,9 +33,12 @@ type Ioc struct {
 	register 			*Register
 	beansN				map[string]*bean.Bean
 	beansT				map[reflect.Type]*bean.Bean
-	insN 				map[string]interface{}
-	insT 				map[reflect.Type]interface{}
-	mutex 				*sync.Mutex
+	singletons 			*scope
+	inactive 			map[reflect.Type]bool	//Concrete types of beans whose conditions fail
+	processors 			[]*bean.Bean			//Beans of post processors
+	processorsReady 	bool
+	mutex 				*sync.RWMutex			//Guards beans registered, never held while building
+	waits 				*sync.Mutex				//Guards resolutions waiting for each other
 }
 
 //Create ioc
@@ -39,9 +49,10 @@ func NewIoc(ctx context.Context) *Ioc {
 		register:register,
 		beansN:make(map[string]*bean.Bean),
 		beansT:make(map[reflect.Type]*bean.Bean),
-		insN:make(map[string]interface{}),
-		insT:make(map[reflect.Type]interface{}),
-		mutex:new(sync.Mutex),
+		singletons:newScope(SINGLETON, nil),
+		inactive:make(map[reflect.Type]bool),
+		mutex:new(sync.RWMutex),
+		waits:new(sync.Mutex),
 	}
 	
 	ioc.appendBeans(register.Init())
@@ -58,44 +69,39 @@ func (ioc *Ioc) RegisterBeans(beans []*bean.Bean) {
 	ioc.appendBeans(ioc.register.RegisterBeans(beans))
 }
 
+//Append beans in order, bean with conditions is appended only if they hold against beans appended before it
 func (ioc *Ioc) appendBeans(beans []*bean.Bean) {
 	if len(beans) > 0 {
 		for _, bean := range beans {
-			ioc.beansN[bean.Name] = bean
-			ioc.beansT[bean.GetAbstractType()] = bean
+			active := bean.Active(ioc)
+			ioc.mutex.Lock()
+			if active {
+				delete(ioc.inactive, bean.GetConcreteType())
+				ioc.beansN[bean.Name] = bean
+				ioc.beansT[bean.GetAbstractType()] = bean
+			} else {
+				ioc.inactive[bean.GetConcreteType()] = true
+			}
+			ioc.processorsReady = false
+			ioc.mutex.Unlock()
 		}
 	}
 }
 
-//Exec process of application
-func (ioc *Ioc) ExecProcess(ctx context.Context, process *process.Process) {
+//Exec process of application, error is returned if the process has no class, can not be created
+//or its execute method returns error
+func (ioc *Ioc) ExecProcess(ctx context.Context, process *process.Process) error {
 	if strings.EqualFold(process.Class, "") {
-		return
-	}
-
-	t := ioc.getType(process.Class)
-	if t == nil {
-		ilog.Error(fmt.Sprintf("process [%s] need regist", process.Class))
-		return
+		return fmt.Errorf("process [%s] has no class", process.Name)
 	}
 
-	p := reflect.New(t)
-
-	ch := make(chan bool)
-	defer close(ch)
-	
-	go func() {
-		for k, v := range process.Params {
-			setField(p, k, v)
-		}
-		ch <- true
-	}()
-
-	setField(p, NAME_KEY, process.Name)
-	setField(p, CTX_KEY, ctx)
-	setField(p, IOC_KEY, ioc)
+	s := newScope(PROCESS, nil)
+	defer ioc.release(s)
 
-	<- ch
+	p, err := ioc.newProcess(context.WithValue(ctx, scopeKey{}, s), process, s)
+	if err != nil {
+		return err
+	}
 	
 	// Do execute
 	var exec string
@@ -106,80 +112,386 @@ func (ioc *Ioc) ExecProcess(ctx context.Context, process *process.Process) {
 	}
 
 	if !strings.EqualFold(exec, "") {
-		p.MethodByName(exec).Call([]reflect.Value{})
+		res := p.MethodByName(exec).Call([]reflect.Value{})
+		if n := len(res); n > 0 && res[n-1].Type() == errorType && !res[n-1].IsNil() {
+			return fmt.Errorf("process [%s] : %w", process.Name, res[n-1].Interface().(error))
+		}
+	}
+	return nil
+}
+
+//Create process and inject its params, s is the scope of the process
+func (ioc *Ioc) newProcess(ctx context.Context, process *process.Process, s *scope) (p reflect.Value, err error) {
+	t := ioc.getType(process.Class)
+	if t == nil {
+		return p, fmt.Errorf("process [%s] need regist", process.Class)
 	}
+
+	p = reflect.New(t)
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("process [%s] : %v", process.Name, r)
+		}
+	}()
+
+	//Params are converted to types of fields, matched by name ignoring case or by tag
+	params, err := system.Convert(process.Params, t)
+	if err != nil {
+		return p, fmt.Errorf("params of process [%s] : %s", process.Name, err)
+	}
+	p.Elem().Set(params)
+
+	setField(p, NAME_KEY, process.Name)
+	setField(p, CTX_KEY, ctx)
+	setField(p, IOC_KEY, &scopedIoc{ioc, s})
+
+	return p, nil
 }
 
 func (ioc *Ioc) BeansByName(name string) *bean.Bean {
-	if b, ok := ioc.beansN[name]; ok {
-		return b
+	b, _ := ioc.beanByName(name)
+	return b
+}
+
+//Get beans which can be wired into field of type t
+func (ioc *Ioc) BeansByType(t reflect.Type) []*bean.Bean {
+	var list []*bean.Bean
+	for _, b := range ioc.beans() {
+		it := b.InstanceType()
+		switch {
+		case b.GetAbstractType() == t, b.GetConcreteType() == t:
+		case t.Kind() == reflect.Interface && it.Implements(t):
+		case it.AssignableTo(t):
+		default:
+			continue
+		}
+		list = append(list, b)
 	}
-	return nil
+	return list
 }
 
 //Get instance by name
 func (ioc *Ioc) InsByClass(i interface{}) interface{} {
-	ioc.mutex.Lock()
-	defer ioc.mutex.Unlock()
-	return ioc.instanceByType(reflect.TypeOf(i))
+	return ioc.insByType(nil, reflect.TypeOf(i))
+}
+
+//Get instance by class, or the error why it can not be created
+func (ioc *Ioc) InsByClassE(i interface{}) (interface{}, error) {
+	return ioc.insByTypeE(nil, reflect.TypeOf(i))
 }
 
 //Get instance by name
 func (ioc *Ioc) InsByName(name string) interface{} {
-	ioc.mutex.Lock()
-	defer ioc.mutex.Unlock()
-	return ioc.instanceByName(name)
+	return ioc.insByName(nil, name)
+}
+
+//Get instance by name, or the error why it can not be created
+func (ioc *Ioc) InsByNameE(name string) (interface{}, error) {
+	return ioc.insByNameE(nil, name)
 }
 
 //Get instance by Type
 func (ioc *Ioc) InsByType(t reflect.Type) interface{} {
+	return ioc.insByType(nil, t)
+}
+
+//Get instance by type, or the error why it can not be created
+func (ioc *Ioc) InsByTypeE(t reflect.Type) (interface{}, error) {
+	return ioc.insByTypeE(nil, t)
+}
+
+//Get instance by name in the scope carried by ctx
+func (ioc *Ioc) InsByNameCtx(ctx context.Context, name string) interface{} {
+	return ioc.insByName(scopeFrom(ctx), name)
+}
+
+//Get instance by type in the scope carried by ctx
+func (ioc *Ioc) InsByTypeCtx(ctx context.Context, t reflect.Type) interface{} {
+	return ioc.insByType(scopeFrom(ctx), t)
+}
+
+//Begin a request scope, the returned func releases it when the request finishes
+func (ioc *Ioc) BeginRequest(ctx context.Context) (context.Context, func()) {
+	return ioc.beginRequest(ctx, scopeFrom(ctx))
+}
+
+func (ioc *Ioc) beginRequest(ctx context.Context, parent *scope) (context.Context, func()) {
+	s := newScope(REQUEST, parent.lookup(PROCESS))
+	return context.WithValue(ctx, scopeKey{}, s), func() {
+		ioc.release(s)
+	}
+}
+
+//Release instances of scope
+func (ioc *Ioc) release(s *scope) {
+	order, ins := s.drain()
+	destroy(order, ins, DESTROY_TIMEOUT)
+}
+
+func (ioc *Ioc) insByName(s *scope, name string) interface{} {
+	return logged(ioc.insByNameE(s, name))
+}
+
+func (ioc *Ioc) insByType(s *scope, t reflect.Type) interface{} {
+	return logged(ioc.insByTypeE(s, t))
+}
+
+func (ioc *Ioc) insByNameE(s *scope, name string) (interface{}, error) {
+	b, ok := ioc.beanByName(name)
+	if !ok {
+		return nil, fmt.Errorf("[%s] : %w", name, ErrNotRegisted)
+	}
+	return ioc.instance(nil, s, b, false)
+}
+
+func (ioc *Ioc) insByTypeE(s *scope, t reflect.Type) (interface{}, error) {
+	ins, err := ioc.instanceByType(s, t)
+	if err == nil && ins == nil {
+		return nil, fmt.Errorf("[%v] : %w", t, ErrNotRegisted)
+	}
+	return ins, err
+}
+
+//Log error except bean not registed, nil is returned on error
+func logged(ins interface{}, err error) interface{} {
+	if err != nil {
+		if !errors.Is(err, ErrNotRegisted) {
+			ilog.Error(err)
+		}
+		return nil
+	}
+	return ins
+}
+
+func (ioc *Ioc) instanceByType(s *scope, t reflect.Type) (interface{}, error) {
+	b, err := ioc.beanByType(t)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return nil, nil
+	}
+	return ioc.instance(nil, s, b, false)
+}
+
+//Get bean of name
+func (ioc *Ioc) beanByName(name string) (*bean.Bean, bool) {
+	ioc.mutex.RLock()
+	defer ioc.mutex.RUnlock()
+	b, ok := ioc.beansN[name]
+	return b, ok
+}
+
+//Get bean of type, struct not registered is added as singleton.
+//Lookup holds the read lock only, the write lock is taken to add the struct
+func (ioc *Ioc) beanByType(t reflect.Type) (*bean.Bean, error) {
+	if t == nil {
+		return nil, nil
+	}
+	ioc.mutex.RLock()
+	b, found, err := ioc.lookupType(t)
+	ioc.mutex.RUnlock()
+	if found || err != nil || t.Kind() != reflect.Struct {
+		return b, err
+	}
+
 	ioc.mutex.Lock()
 	defer ioc.mutex.Unlock()
-	return ioc.instanceByType(t)
+	if b, ok := ioc.beansT[t]; ok {
+		return b, nil
+	}
+	if ioc.inactive[t] {
+		return nil, nil
+	}
+	b = &bean.Bean{
+		Name: t.Name(),
+		Scope: SINGLETON,
+	}
+	b.SetAbstractType(t)
+	b.SetConcreteType(t)
+	ioc.beansT[t] = b
+	return b, nil
+}
+
+//Find registered bean of type t, found is false if there is none. Mutex is held by caller
+func (ioc *Ioc) lookupType(t reflect.Type) (b *bean.Bean, found bool, err error) {
+	if b, ok := ioc.beansT[t]; ok {
+		return b, true, nil
+	}
+	if t.Kind() == reflect.Interface {
+		b, err := ioc.beanByInterface(t)
+		return b, b != nil, err
+	}
+	for _, b := range ioc.beansN {
+		if b.GetConcreteType() == t {
+			return b, true, nil
+		}
+	}
+	return nil, ioc.inactive[t], nil
 }
 
-func (ioc *Ioc) instanceByName(name string) interface{} {
-	var(
-		instance interface{}
-		exist bool
-	)
-	if instance, exist = ioc.insN[name];!exist {
-		instance = ioc.buildInstance(ioc.getType(name))
+//Get the only bean implementing interface t, nil if none implements it. Mutex is held by caller
+func (ioc *Ioc) beanByInterface(t reflect.Type) (*bean.Bean, error) {
+	seen := make(map[*bean.Bean]bool)
+	var found []*bean.Bean
+	check := func(b *bean.Bean) {
+		if !seen[b] && b.InstanceType().Implements(t) {
+			found = append(found, b)
+		}
+		seen[b] = true
+	}
+	for _, b := range ioc.beansN {
+		check(b)
+	}
+	for _, b := range ioc.beansT {
+		check(b)
+	}
+	switch len(found) {
+	case 0:
+		return nil, nil
+	case 1:
+		return found[0], nil
+	default:
+		var names []string
+		for _, b := range found {
+			names = append(names, b.Name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("[%s] is implemented by more than one bean : %s", t.String(), strings.Join(names, ", "))
 	}
-	return instance
 }
 
-func (ioc *Ioc) instanceByType(t reflect.Type) interface{} {
-	var(
-		instance interface{}
-		exist bool
-	)
-	if instance, exist = ioc.insT[t];!exist {
-		instance = ioc.buildInstance(t)
+//All beans sorted by name
+func (ioc *Ioc) beans() []*bean.Bean {
+	ioc.mutex.RLock()
+	defer ioc.mutex.RUnlock()
+	seen := make(map[*bean.Bean]bool)
+	var list []*bean.Bean
+	for _, b := range ioc.beansN {
+		seen[b] = true
+		list = append(list, b)
+	}
+	for _, b := range ioc.beansT {
+		if !seen[b] {
+			seen[b] = true
+			list = append(list, b)
+		}
 	}
-	return instance
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list
 }
 
-//Create new instance
-func (ioc *Ioc) buildInstance(t reflect.Type) interface{} {
-	if t == nil {
-		return nil
+//Get instance of bean, s is the scope of the caller, p is the path resolving it,
+//ptr tells whether it is asked by a pointer field.
+//Instance is built once in its scope, built instances are read without locking
+func (ioc *Ioc) instance(p path, s *scope, b *bean.Bean, ptr bool) (ins interface{}, err error) {
+	owner := ioc.singletons
+	switch b.Scope {
+	case PROTOTYPE:
+		if ins, err := p.cycle(b, ptr); ins != nil || err != nil {
+			return ins, err
+		}
+		p, _ = p.push(b, ptr)
+		ins, _, err := ioc.build(p, s, b)
+		return ins, err
+	case PROCESS, REQUEST:
+		if owner = s.lookup(b.Scope); owner == nil {
+			return nil, fmt.Errorf("bean [%s] of %s scope can not be resolved outside of a %s", p.chain(b), b.Scope, b.Scope)
+		}
 	}
-	
-	scope := SINGLETON
-	
-	if bean, ok := ioc.beansT[t]; ok {
-		t = bean.GetConcreteType()
-		scope = bean.Scope
+
+	if ins, ok := owner.get(b); ok {
+		return ins, nil
+	}
+	if ins, err := p.cycle(b, ptr); ins != nil || err != nil {
+		return ins, err
 	}
+
+	np, fr := p.push(b, ptr)
+	e, loaded := owner.entry(b, fr)
+	if loaded {
+		return ioc.wait(p, e, b, ptr)
+	}
+	//Entry is finished even if building panics, or its waiters would block forever
+	var raw interface{}
+	defer func() {
+		if r := recover(); r != nil {
+			ins, raw, err = nil, nil, beanError(np, "Build", fmt.Errorf("panic : %v", r))
+		}
+		owner.finish(b, e, ins, raw, err)
+	}()
+	ins, raw, err = ioc.build(np, owner, b)
+	return ins, err
+}
+
+//Wait for entry e of bean b built by another resolution.
+//If that resolution waits for the one of p, it is a cycle across goroutines,
+//which gets the allocated instance like a cycle in one path if b is asked by a pointer field
+func (ioc *Ioc) wait(p path, e *entry, b *bean.Bean, ptr bool) (interface{}, error) {
+	if res := p.resolution(); res != nil {
+		ioc.waits.Lock()
+		for r := e.frame.res; r != nil && !e.built(); {
+			if r == res {
+				ioc.waits.Unlock()
+				if ptr && e.frame.ins.IsValid() {
+					return e.frame.ins.Interface(), nil
+				}
+				return nil, fmt.Errorf("circular dependency with another lookup : %s", p.chain(b))
+			}
+			if r.waiting == nil {
+				break
+			}
+			r = r.waiting.frame.res
+		}
+		res.waiting = e
+		ioc.waits.Unlock()
+
+		defer func() {
+			ioc.waits.Lock()
+			res.waiting = nil
+			ioc.waits.Unlock()
+		}()
+	}
+	<-e.done
+	return e.ins, e.err
+}
+
+//Create instance of bean on top of path p and pass it through post processors.
+//Returns the instance exposed and the raw one built
+func (ioc *Ioc) build(p path, s *scope, b *bean.Bean) (interface{}, interface{}, error) {
+	raw, err := ioc.buildInstance(p, s, b)
+	if err != nil {
+		return nil, nil, err
+	}
+	ins, err := ioc.postProcess(p, b, raw)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ins, raw, nil
+}
+
+//Create new instance of bean on top of path p, dependencies are resolved in scope s
+func (ioc *Ioc) buildInstance(p path, s *scope, b *bean.Bean) (interface{}, error) {
+	if b.GetFactory().IsValid() {
+		return ioc.buildByFactory(p, s, b)
+	}
+
+	t := b.GetConcreteType()
 	ins := reflect.New(t)
 
+	p[len(p)-1].ins = ins
+
 	setField(ins, CTX_KEY, ioc.ctx)
 
 	//Execute construct method of instance
 	cm := ins.MethodByName(CONSTRUCT_FUNC)
 	if cm.IsValid() {
-		cm.Call(nil)
+		if _, err := callHook(p, CONSTRUCT_FUNC, cm, nil); err != nil {
+			return nil, err
+		}
 	}
 
 	//Inject construct params
@@ -188,62 +500,32 @@ func (ioc *Ioc) buildInstance(t reflect.Type) interface{} {
 		if !f.CanSet() {
 			continue
 		}
-		switch f.Kind() {
-		case reflect.Struct:
-			if tag := t.Field(index).Tag.Get("wired"); !strings.EqualFold(tag, "") {
-				if i := ioc.instanceByType(f.Type()); i != nil {
-					f.Set(reflect.ValueOf(i).Elem())
-				}
-			}
-			break
-		case reflect.Ptr:
-			if tag := t.Field(index).Tag.Get("wired"); !strings.EqualFold(tag, "") {
-				if i := ioc.instanceByType(f.Type().Elem()); i != nil {
-					f.Set(reflect.ValueOf(i))
-				}
-			}
-			break
-		case reflect.String:
-			if tag := t.Field(index).Tag.Get("value"); !strings.EqualFold(tag, "") {
-				f.Set(reflect.ValueOf(system.Conf.GetString(tag)))
-			}
-			break
-		case reflect.Int:
-			if tag := t.Field(index).Tag.Get("value"); !strings.EqualFold(tag, "") {
-				f.Set(reflect.ValueOf(system.Conf.GetInt(tag)))
-			}
-			break
-		case reflect.Bool:
-			if tag := t.Field(index).Tag.Get("value"); !strings.EqualFold(tag, "") {
-				f.Set(reflect.ValueOf(system.Conf.GetBoolean(tag)))
-			}
-			break
-		default:
-			break
+		if err := ioc.wire(p, s, t.Field(index), f); err != nil {
+			return nil, err
+		}
+		if err := ioc.value(p, t.Field(index), f); err != nil {
+			return nil, err
 		}
 	}
 
 	//Execute init method of instance
 	im := ins.MethodByName(INIT_FUNC)
 	if im.IsValid() {
+		var args []reflect.Value
 		if im.Type().NumIn() > 0 {
-			im.Call([]reflect.Value{ins})
-		} else {
-			im.Call(nil)
+			args = []reflect.Value{ins}
+		}
+		if _, err := callHook(p, INIT_FUNC, im, args); err != nil {
+			return nil, err
 		}
 	}
 
-	if ins.Interface() != nil && strings.EqualFold(scope, SINGLETON) {
-		ioc.insN[t.Name()] = ins.Interface()
-		ioc.insT[t] = ins.Interface()
-	}
-
-	return ins.Interface()
+	return ins.Interface(), nil
 }
 
 //Get type of bean
 func (ioc *Ioc) getType(name string) reflect.Type{
-	if t, ok := ioc.beansN[name]; ok {
+	if t, ok := ioc.beanByName(name); ok {
 		return t.GetConcreteType()
 	}
 	return nil
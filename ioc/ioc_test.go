@@ -0,0 +1,134 @@
+package ioc
+
+import (
+	"context"
+	"errors"
+	"github.com/itea-tgl/itea-go/ioc/bean"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var constructs int32
+
+type onceBean struct{}
+
+func (ob *onceBean) Construct() {
+	atomic.AddInt32(&constructs, 1)
+	time.Sleep(10 * time.Millisecond)
+}
+
+func TestConcurrentSingleton(t *testing.T) {
+	atomic.StoreInt32(&constructs, 0)
+	ioc := NewIoc(context.Background())
+	ioc.Register([]interface{}{onceBean{}})
+
+	var wg sync.WaitGroup
+	list := make([]interface{}, 50)
+	errs := make([]error, 50)
+	for i := range list {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			list[i], errs[i] = ioc.InsByNameE("onceBean")
+		}(i)
+	}
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&constructs); n != 1 {
+		t.Fatalf("singleton constructed %d times", n)
+	}
+	for i := range list {
+		if errs[i] != nil {
+			t.Fatal(errs[i])
+		}
+		if list[i] != list[0] {
+			t.Fatalf("lookup %d got another instance", i)
+		}
+	}
+}
+
+//Beans wiring each other, Construct waits so that both lookups start before wiring
+type cycleA struct {
+	B 		*cycleB 	`wired:"true"`
+}
+
+func (a *cycleA) Construct() {
+	time.Sleep(50 * time.Millisecond)
+}
+
+type cycleB struct {
+	A 		*cycleA 	`wired:"true"`
+}
+
+func (b *cycleB) Construct() {
+	time.Sleep(50 * time.Millisecond)
+}
+
+//Look up names at once, failing if any lookup hangs
+func lookupAll(t *testing.T, ioc *Ioc, names ...string) ([]interface{}, []error) {
+	list, errs := make([]interface{}, len(names)), make([]error, len(names))
+	done := make(chan bool)
+	go func() {
+		var wg sync.WaitGroup
+		for i, name := range names {
+			wg.Add(1)
+			go func(i int, name string) {
+				defer wg.Done()
+				list[i], errs[i] = ioc.InsByNameE(name)
+			}(i, name)
+		}
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("lookups deadlocked")
+	}
+	return list, errs
+}
+
+func TestCrossGoroutineCycle(t *testing.T) {
+	ioc := NewIoc(context.Background())
+	ioc.Register([]interface{}{cycleA{}, cycleB{}})
+
+	list, errs := lookupAll(t, ioc, "cycleA", "cycleB")
+	for _, err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	a, b := list[0].(*cycleA), list[1].(*cycleB)
+	if a.B != b || b.A != a {
+		t.Fatal("beans of pointer cycle are not wired with each other")
+	}
+}
+
+//Post processor panicking on victim
+type panicProcessor struct{}
+
+func (pp *panicProcessor) PostProcess(b *bean.Bean, ins interface{}) (interface{}, error) {
+	if _, ok := ins.(*victim); ok {
+		panic("bad processor")
+	}
+	return ins, nil
+}
+
+type victim struct{}
+
+func TestPanicReleasesWaiters(t *testing.T) {
+	ioc := NewIoc(context.Background())
+	ioc.Register([]interface{}{panicProcessor{}, victim{}})
+
+	for i := 0; i < 2; i++ {
+		_, errs := lookupAll(t, ioc, "victim", "victim")
+		for _, err := range errs {
+			var be *BeanError
+			if !errors.As(err, &be) || be.Name != "victim" {
+				t.Fatalf("lookup %d got %v, want error of bean victim", i, err)
+			}
+		}
+	}
+}
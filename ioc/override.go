@@ -0,0 +1,123 @@
+package ioc
+
+import (
+	"fmt"
+	"github.com/itea-tgl/itea-go/ioc/bean"
+	"reflect"
+)
+
+//Create container inheriting beans registered so far, with its own singletons.
+//Beans registered or overridden in the child are not seen by ioc
+func (ioc *Ioc) Child() *Ioc {
+	child := NewIoc(ioc.ctx)
+
+	ioc.mutex.RLock()
+	defer ioc.mutex.RUnlock()
+	for n, b := range ioc.beansN {
+		child.beansN[n] = b
+	}
+	for t, b := range ioc.beansT {
+		child.beansT[t] = b
+	}
+	for t := range ioc.inactive {
+		child.inactive[t] = true
+	}
+	return child
+}
+
+//Destroy created singletons, they are created again on next lookup
+func (ioc *Ioc) Reset() {
+	ioc.Destroy(DESTROY_TIMEOUT)
+}
+
+//Replace bean of name with v, singletons created are reset.
+//v is an instance, a struct value taken as instance, or a factory like in Register
+func (ioc *Ioc) OverrideByName(name string, v interface{}) error {
+	old, ok := ioc.beanByName(name)
+	if !ok {
+		return fmt.Errorf("[%s] : %w", name, ErrNotRegisted)
+	}
+	return ioc.override(old, old.GetAbstractType(), v)
+}
+
+//Replace bean wired into fields of type of class with v, singletons created are reset.
+//Interface is given by a nil pointer like (*IHandler)(nil), v is like in OverrideByName
+func (ioc *Ioc) OverrideByType(class interface{}, v interface{}) error {
+	t := classType(class)
+	if t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct {
+		t = t.Elem()
+	}
+	ioc.mutex.RLock()
+	old, ok := ioc.beansT[t]
+	ioc.mutex.RUnlock()
+	if !ok {
+		switch list := ioc.BeansByType(t); len(list) {
+		case 0:
+		case 1:
+			old = list[0]
+		default:
+			return fmt.Errorf("[%s] is registed by more than one bean, override by name", t.String())
+		}
+	}
+	return ioc.override(old, t, v)
+}
+
+//Replace bean old with bean of v which can be wired into fields of type t, old is nil if nothing to replace
+func (ioc *Ioc) override(old *bean.Bean, t reflect.Type, v interface{}) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("override [%s] : %v", t.String(), r)
+		}
+	}()
+
+	var nb *bean.Bean
+	switch vt := reflect.TypeOf(v); {
+	case vt == nil:
+		return fmt.Errorf("override [%s] with nil", t.String())
+	case vt.Kind() == reflect.Func:
+		nb = ioc.register.Register([]interface{}{v})[0]
+	case vt.Kind() == reflect.Struct:
+		//Struct value is an instance with fields set by the caller, exposed by pointer like built ones
+		p := reflect.New(vt)
+		p.Elem().Set(reflect.ValueOf(v))
+		nb = ioc.register.RegisterBeans([]*bean.Bean{{Concrete: instanceFactory(p.Interface()).Interface()}})[0]
+	default:
+		nb = ioc.register.RegisterBeans([]*bean.Bean{{Concrete: instanceFactory(v).Interface()}})[0]
+	}
+
+	it := nb.InstanceType()
+	if (t.Kind() == reflect.Interface && !it.Implements(t)) || (t.Kind() != reflect.Interface && it != reflect.PtrTo(t)) {
+		return fmt.Errorf("can not override [%s] with %s", t.String(), it.String())
+	}
+
+	ioc.mutex.Lock()
+	if old != nil {
+		nb.Name, nb.Scope = old.Name, old.Scope
+		for n, b := range ioc.beansN {
+			if b == old {
+				delete(ioc.beansN, n)
+			}
+		}
+		for bt, b := range ioc.beansT {
+			if b == old {
+				delete(ioc.beansT, bt)
+			}
+		}
+	}
+	ioc.beansN[nb.Name] = nb
+	ioc.beansT[t] = nb
+	ioc.processorsReady = false
+	ioc.mutex.Unlock()
+
+	ioc.Reset()
+	return nil
+}
+
+//Factory returning instance v
+func instanceFactory(v interface{}) reflect.Value {
+	vv := reflect.ValueOf(v)
+	ft := reflect.FuncOf(nil, []reflect.Type{vv.Type()}, false)
+	return reflect.MakeFunc(ft, func([]reflect.Value) []reflect.Value {
+		return []reflect.Value{vv}
+	})
+}
@@ -0,0 +1,26 @@
+package ioc
+
+import (
+	"context"
+	"testing"
+)
+
+type overridden struct {
+	Addr 		string
+}
+
+func TestOverrideWithStructValue(t *testing.T) {
+	ioc := NewIoc(context.Background())
+	ioc.Register([]interface{}{overridden{}})
+
+	if err := ioc.OverrideByName("overridden", overridden{Addr: "mock"}); err != nil {
+		t.Fatal(err)
+	}
+	o, err := Get[*overridden](ioc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.Addr != "mock" {
+		t.Fatalf("addr is %q, want the one of the value overriding", o.Addr)
+	}
+}
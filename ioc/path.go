@@ -0,0 +1,92 @@
+package ioc
+
+import (
+	"fmt"
+	"github.com/itea-tgl/itea-go/ioc/bean"
+	"reflect"
+	"strings"
+)
+
+//Bean under construction
+type frame struct {
+	bean 		*bean.Bean
+	ins 		reflect.Value	//Allocated instance, not wired yet
+	ptr 		bool			//Reached through a pointer field
+	res 		*resolution		//Resolution the frame belongs to
+}
+
+//Lookup of a bean with all the beans it builds, waiting at most for one entry built by another resolution
+type resolution struct {
+	waiting 	*entry
+}
+
+//Resolution stack, from the bean asked for to the bean being built
+type path []*frame
+
+//Push frame of bean into path
+func (p path) push(b *bean.Bean, ptr bool) (path, *frame) {
+	f := &frame{bean: b, ptr: ptr, res: p.resolution()}
+	if f.res == nil {
+		f.res = new(resolution)
+	}
+	np := make(path, len(p), len(p) + 1)
+	copy(np, p)
+	return append(np, f), f
+}
+
+//Resolution of path, nil for empty path
+func (p path) resolution() *resolution {
+	if len(p) == 0 {
+		return nil
+	}
+	return p[len(p)-1].res
+}
+
+//Find frame of bean b which is under construction
+func (p path) find(b *bean.Bean) int {
+	for i, f := range p {
+		if f.bean == b {
+			return i
+		}
+	}
+	return -1
+}
+
+//Check cycle closed by asking b from the top of path.
+//A cycle going only through pointer fields gets the allocated instance, which is wired when its frame returns
+func (p path) cycle(b *bean.Bean, ptr bool) (interface{}, error) {
+	i := p.find(b)
+	if i < 0 {
+		return nil, nil
+	}
+	if p.lazy(i, ptr) && p[i].ins.IsValid() {
+		return p[i].ins.Interface(), nil
+	}
+	return nil, fmt.Errorf("circular dependency : %s", p.chain(b))
+}
+
+//Whether the cycle from p[i] back to it, closed by a field of kind ptr, can be wired lazily
+func (p path) lazy(i int, ptr bool) bool {
+	lazy := ptr && p[i].bean.Scope != PROTOTYPE
+	for _, f := range p[i+1:] {
+		lazy = lazy && f.ptr
+	}
+	return lazy
+}
+
+//Readable chain of path, ended with b
+func (p path) chain(b *bean.Bean) string {
+	return strings.Join(append(p.names(), b.Name), " -> ")
+}
+
+func (p path) String() string {
+	return strings.Join(p.names(), " -> ")
+}
+
+func (p path) names() []string {
+	var names []string
+	for _, f := range p {
+		names = append(names, f.bean.Name)
+	}
+	return names
+}
@@ -0,0 +1,75 @@
+package ioc
+
+import (
+	"fmt"
+	"github.com/itea-tgl/itea-go/ioc/bean"
+	"github.com/itea-tgl/itea-go/ioc/iface"
+	"reflect"
+	"sort"
+)
+
+const POST_PROCESS_FUNC = "PostProcess"
+
+var postProcessorType = reflect.TypeOf(new(iface.IPostProcessor)).Elem()
+
+//Whether bean is a post processor
+func isPostProcessor(b *bean.Bean) bool {
+	return b.InstanceType().Implements(postProcessorType)
+}
+
+//Beans of post processors, sorted by Order of bean then by name.
+//The list is kept until beans are registered again
+func (ioc *Ioc) postProcessors() []*bean.Bean {
+	ioc.mutex.RLock()
+	list, ok := ioc.processors, ioc.processorsReady
+	ioc.mutex.RUnlock()
+	if ok {
+		return list
+	}
+
+	list = nil
+	for _, b := range ioc.beans() {
+		if isPostProcessor(b) {
+			list = append(list, b)
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Order < list[j].Order
+	})
+
+	ioc.mutex.Lock()
+	ioc.processors, ioc.processorsReady = list, true
+	ioc.mutex.Unlock()
+	return list
+}
+
+//Pass instance of bean on top of path p through post processors, each gets the instance returned by the one before.
+//Post processors and beans built for them are not post processed, neither are instances wired early into a cycle
+func (ioc *Ioc) postProcess(p path, b *bean.Bean, ins interface{}) (interface{}, error) {
+	for _, f := range p {
+		if isPostProcessor(f.bean) {
+			return ins, nil
+		}
+	}
+	for _, pb := range ioc.postProcessors() {
+		i, err := ioc.instance(p, nil, pb, true)
+		if err != nil {
+			return nil, err
+		}
+		pp, ok := i.(iface.IPostProcessor)
+		if !ok {
+			continue
+		}
+		//Called as a hook so that a panic is reported with the path like the ones of Construct and Init
+		res, err := callHook(p, POST_PROCESS_FUNC, reflect.ValueOf(pp.PostProcess), []reflect.Value{reflect.ValueOf(b), reflect.ValueOf(&ins).Elem()})
+		if err != nil {
+			be := err.(*BeanError)
+			be.Err = fmt.Errorf("post processor [%s] : %w", pb.Name, be.Err)
+			return nil, be
+		}
+		if ins = res[0].Interface(); ins == nil {
+			return nil, beanError(p, POST_PROCESS_FUNC, fmt.Errorf("[%s] returns nil", pb.Name))
+		}
+	}
+	return ins, nil
+}
@@ -0,0 +1,58 @@
+package ioc
+
+import (
+	"context"
+	"errors"
+	"github.com/itea-tgl/itea-go/ioc/bean"
+	"strings"
+	"testing"
+)
+
+var errRejected = errors.New("not allowed")
+
+//Post processor panicking on panicked and rejecting rejected
+type checkProcessor struct{}
+
+func (cp *checkProcessor) PostProcess(b *bean.Bean, ins interface{}) (interface{}, error) {
+	switch ins.(type) {
+	case *panicked:
+		panic("bad processor")
+	case *rejected:
+		return nil, errRejected
+	}
+	return ins, nil
+}
+
+type panicked struct{}
+
+type rejected struct{}
+
+func TestPostProcessFail(t *testing.T) {
+	ioc := NewIoc(context.Background())
+	ioc.Register([]interface{}{checkProcessor{}, panicked{}, rejected{}})
+
+	cases := []struct {
+		name 		string
+		msg 		string
+		cause 		error
+	}{
+		{"panicked", "PostProcess of bean [panicked] fail : post processor [checkProcessor] : panic : bad processor", nil},
+		{"rejected", "PostProcess of bean [rejected] fail : post processor [checkProcessor] : not allowed", errRejected},
+	}
+	for _, c := range cases {
+		_, err := ioc.InsByNameE(c.name)
+		var be *BeanError
+		if !errors.As(err, &be) {
+			t.Fatalf("%s got %v, want BeanError", c.name, err)
+		}
+		if be.Hook != POST_PROCESS_FUNC || be.Name != c.name {
+			t.Errorf("%s got error of hook %s of bean %s", c.name, be.Hook, be.Name)
+		}
+		if !strings.EqualFold(err.Error(), c.msg) {
+			t.Errorf("%s got message %q, want %q", c.name, err.Error(), c.msg)
+		}
+		if c.cause != nil && !errors.Is(err, c.cause) {
+			t.Errorf("%s error does not wrap %v", c.name, c.cause)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package ioc
+
+import (
+	"fmt"
+	"github.com/itea-tgl/itea-go/ilog"
+	"github.com/itea-tgl/itea-go/system"
+	"reflect"
+	"strings"
+	"sync/atomic"
+)
+
+const REFRESH_TAG = "refresh"
+
+//Config value refreshed on reload, like field `Port ioc.Value[int]` tagged by `value:"app.port" refresh:"true"`.
+//It is read by Get while config reloads, so the bean holding it needs no lock
+type Value[T any] struct {
+	v 			atomic.Value	//Holds *T
+}
+
+//Get current value
+func (v *Value[T]) Get() T {
+	if p, ok := v.v.Load().(*T); ok {
+		return *p
+	}
+	var zero T
+	return zero
+}
+
+func (v *Value[T]) valueType() reflect.Type {
+	return typeOf[T]()
+}
+
+func (v *Value[T]) store(x reflect.Value) {
+	p := new(T)
+	reflect.ValueOf(p).Elem().Set(x)
+	v.v.Store(p)
+}
+
+//Field of Value
+type holder interface {
+	valueType() reflect.Type
+	store(x reflect.Value)
+}
+
+var holderType = reflect.TypeOf(new(holder)).Elem()
+
+//Whether field sf is tagged to be refreshed
+func refreshed(sf reflect.StructField) bool {
+	return !strings.EqualFold(sf.Tag.Get(VALUE_TAG), "") && strings.EqualFold(sf.Tag.Get(REFRESH_TAG), "true")
+}
+
+//Check field tagged to be refreshed is a Value, other fields can not be changed safely
+func checkRefresh(p path, sf reflect.StructField) error {
+	if refreshed(sf) && !reflect.PtrTo(sf.Type).Implements(holderType) {
+		return fmt.Errorf("field %s of [%s] should be ioc.Value to be refreshed", sf.Name, p)
+	}
+	return nil
+}
+
+//Store config again into fields of Value tagged by `value` and `refresh:"true"` of built singletons
+//if their config keys changed. Field failing to convert keeps its value
+func (ioc *Ioc) Refresh(keys []string) {
+	order, ins := ioc.singletons.built()
+	for _, b := range order {
+		v := reflect.ValueOf(ins[b])
+		if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+			continue
+		}
+		p, _ := path(nil).push(b, false)
+		t := v.Elem().Type()
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if !refreshed(sf) || !system.Changed(parseValueTag(sf.Tag.Get(VALUE_TAG)).key, keys) {
+				continue
+			}
+			if err := checkRefresh(p, sf); err != nil {
+				ilog.Error(err)
+				continue
+			}
+			if f := v.Elem().Field(i); f.CanSet() {
+				if err := ioc.value(p, sf, f); err != nil {
+					ilog.Error(err)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package ioc
+
+import (
+	"context"
+	"fmt"
+	"github.com/itea-tgl/itea-go/ilog"
+	"github.com/itea-tgl/itea-go/system"
+	"os"
+	"sync"
+	"testing"
+)
+
+const refreshFile = "refresh_test.yml"
+
+type refreshBean struct {
+	Port 		Value[int] 	`value:"refresh_test.port" refresh:"true"`
+}
+
+//Load config with port, file is removed when test finishes
+func initConf(t *testing.T, port int) {
+	ilog.Init(nil)
+	writeConf(t, port)
+	t.Cleanup(func() {
+		os.Remove(refreshFile)
+	})
+	system.InitConf("/" + refreshFile)
+}
+
+func writeConf(t *testing.T, port int) {
+	if err := os.WriteFile(refreshFile, []byte(fmt.Sprintf("port: %d\n", port)), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+//Run with -race, reading refreshed value while config reloads should not race
+func TestRefreshWhileReading(t *testing.T) {
+	initConf(t, 1)
+
+	ioc := NewIoc(context.Background())
+	system.Conf.Subscribe(ioc.Refresh)
+	ioc.Register([]interface{}{refreshBean{}})
+	rb := ioc.InsByName("refreshBean").(*refreshBean)
+	if n := rb.Port.Get(); n != 1 {
+		t.Fatalf("port is %d, not 1", n)
+	}
+
+	stop := make(chan bool)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				rb.Port.Get()
+			}
+		}
+	}()
+	for port := 2; port <= 20; port++ {
+		writeConf(t, port)
+		if err := system.Conf.Reload(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(stop)
+	wg.Wait()
+
+	if n := rb.Port.Get(); n != 20 {
+		t.Fatalf("port is %d, not 20", n)
+	}
+}
+
+type plainRefreshBean struct {
+	Port 		int 	`value:"refresh_test.port" refresh:"true"`
+}
+
+func TestRefreshNeedsValue(t *testing.T) {
+	initConf(t, 1)
+	ioc := NewIoc(context.Background())
+	ioc.Register([]interface{}{plainRefreshBean{}})
+	if err := ioc.Validate(nil); err == nil {
+		t.Fatal("refreshed field of int should fail validation")
+	}
+}
+
+type caseRefreshBean struct {
+	Port 		Value[int] 	`value:"Refresh_Test.Port" refresh:"true"`
+}
+
+func TestRefreshIgnoresCase(t *testing.T) {
+	initConf(t, 1)
+	ioc := NewIoc(context.Background())
+	system.Conf.Subscribe(ioc.Refresh)
+	ioc.Register([]interface{}{caseRefreshBean{}})
+	rb := ioc.InsByName("caseRefreshBean").(*caseRefreshBean)
+
+	writeConf(t, 2)
+	if err := system.Conf.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if n := rb.Port.Get(); n != 2 {
+		t.Fatalf("port is %d, not 2", n)
+	}
+}
@@ -1,16 +1,23 @@
 package ioc
 
 import (
+	"fmt"
 	"github.com/itea-tgl/itea-go/ioc/bean"
 	"github.com/itea-tgl/itea-go/process/cron"
 	"github.com/itea-tgl/itea-go/process/ihttp"
 	"github.com/itea-tgl/itea-go/process/kafka"
 	"github.com/itea-tgl/itea-go/process/thrift"
+	"github.com/itea-tgl/itea-go/system"
 	"reflect"
 	"strings"
 )
 
-const SINGLETON = "singleton"
+const (
+	SINGLETON 	= bean.SINGLETON
+	PROTOTYPE 	= bean.PROTOTYPE
+	PROCESS 	= bean.PROCESS
+	REQUEST 	= bean.REQUEST
+)
 
 type Register struct {
 
@@ -33,6 +40,9 @@ func (r *Register) process() []interface{} {
 func (r *Register) module() []interface{} {
 	return [] interface{}{
 		//ihttp.Route{},
+		func() *system.Config {
+			return system.Conf
+		},
 	}
 }
 
@@ -41,11 +51,19 @@ func (r *Register) Init() []*bean.Bean {
 	return r.Register(append(r.process(), r.module()...))
 }
 
-//Register beans
+//Register beans, class is a struct, a factory function or a bean
 func (r *Register) Register(class []interface{}) []*bean.Bean {
 	var beans []*bean.Bean
 	for _, b := range class {
+		if b, ok := b.(*bean.Bean); ok {
+			beans = append(beans, r.RegisterBeans([]*bean.Bean{b})...)
+			continue
+		}
 		t := reflect.TypeOf(b)
+		if t.Kind() == reflect.Func {
+			beans = append(beans, r.RegisterBeans([]*bean.Bean{{Concrete: b}})...)
+			continue
+		}
 		bean := &bean.Bean{
 			Name: t.Name(),
 			Scope: SINGLETON,
@@ -67,19 +85,37 @@ func (r *Register) RegisterBeans(beans []*bean.Bean) []*bean.Bean {
 		}
 
 		tc := reflect.TypeOf(bean.Concrete)
+		if tc.Kind() == reflect.Func {
+			//Function is the factory of bean of its result type
+			ft, err := factoryType(tc)
+			if err != nil {
+				panic(fmt.Sprintf("factory %s : %s", tc.String(), err))
+			}
+			bean.SetFactory(reflect.ValueOf(bean.Concrete))
+			tc = ft
+		}
 		bean.SetConcreteType(tc)
-		if bean.Abstract == nil {
+
+		ta := tc
+		if bean.Abstract != nil {
+			//Interface is given as abstract by a nil pointer, like (*IHandler)(nil)
+			ta = classType(bean.Abstract)
+		} else if !bean.GetFactory().IsValid() {
 			bean.Abstract = bean.Concrete
 		}
-		ta := reflect.TypeOf(bean.Abstract)
 		bean.SetAbstractType(ta)
 
 		if strings.EqualFold(bean.Name, "") {
 			bean.Name = ta.Name()
 		}
 
-		if strings.EqualFold(bean.Scope, "") {
-			bean.Scope = "singleton"
+		switch strings.ToLower(bean.Scope) {
+		case "":
+			bean.Scope = SINGLETON
+		case SINGLETON, PROTOTYPE, PROCESS, REQUEST:
+			bean.Scope = strings.ToLower(bean.Scope)
+		default:
+			panic(fmt.Sprintf("scope [%s] of bean [%s] is not supported", bean.Scope, bean.Name))
 		}
 	}
 	return beans
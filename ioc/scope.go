@@ -0,0 +1,174 @@
+package ioc
+
+import (
+	"context"
+	"github.com/itea-tgl/itea-go/ioc/bean"
+	"reflect"
+	"sync"
+)
+
+type scopeKey struct{}
+
+//Instance of bean in a scope, built once by the resolution creating the entry
+type entry struct {
+	done 		chan struct{}	//Closed when built
+	ins 		interface{}		//Instance exposed, may be decorated by post processors
+	raw 		interface{}		//Instance built, which is destroyed and refreshed
+	err 		error
+	frame 		*frame			//Frame building the instance
+}
+
+//Whether the instance is built
+func (e *entry) built() bool {
+	select {
+	case <-e.done:
+		return true
+	default:
+		return false
+	}
+}
+
+//Instances shared by the container, a process or a request
+type scope struct {
+	name 		string
+	parent 		*scope
+	entries 	*sync.Map		//Entry of bean
+	mutex 		*sync.Mutex
+	order 		[]*bean.Bean	//Beans in order of creation
+}
+
+//Create scope
+func newScope(name string, parent *scope) *scope {
+	return &scope{
+		name: name,
+		parent: parent,
+		entries: new(sync.Map),
+		mutex: new(sync.Mutex),
+	}
+}
+
+//Get built instance of bean, without locking
+func (s *scope) get(b *bean.Bean) (interface{}, bool) {
+	v, ok := s.entries.Load(b)
+	if !ok {
+		return nil, false
+	}
+	e := v.(*entry)
+	if !e.built() || e.err != nil {
+		return nil, false
+	}
+	return e.ins, true
+}
+
+//Get entry of bean, a new entry built by frame fr is added if there is none.
+//Returns whether the entry is added by another resolution
+func (s *scope) entry(b *bean.Bean, fr *frame) (*entry, bool) {
+	v, loaded := s.entries.LoadOrStore(b, &entry{
+		done: make(chan struct{}),
+		frame: fr,
+	})
+	return v.(*entry), loaded
+}
+
+//Finish building entry e of bean, entry failed is removed so the bean is built again on next lookup
+func (s *scope) finish(b *bean.Bean, e *entry, ins interface{}, raw interface{}, err error) {
+	e.ins, e.raw, e.err = ins, raw, err
+	if err != nil {
+		s.entries.Delete(b)
+	} else {
+		s.mutex.Lock()
+		s.order = append(s.order, b)
+		s.mutex.Unlock()
+	}
+	close(e.done)
+}
+
+//Take all built instances away, in order of creation. Instances are the ones before post processing
+func (s *scope) drain() ([]*bean.Bean, map[*bean.Bean]interface{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	order, ins := s.order, make(map[*bean.Bean]interface{})
+	for _, b := range order {
+		if v, ok := s.entries.Load(b); ok {
+			ins[b] = v.(*entry).raw
+			s.entries.Delete(b)
+		}
+	}
+	s.order = nil
+	return order, ins
+}
+
+//Built instances in order of creation, before post processing
+func (s *scope) built() ([]*bean.Bean, map[*bean.Bean]interface{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	order, ins := append([]*bean.Bean{}, s.order...), make(map[*bean.Bean]interface{})
+	for _, b := range order {
+		if v, ok := s.entries.Load(b); ok {
+			ins[b] = v.(*entry).raw
+		}
+	}
+	return order, ins
+}
+
+//Find scope of name from s up to its parents
+func (s *scope) lookup(name string) *scope {
+	for ; s != nil; s = s.parent {
+		if s.name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+//Get scope carried by ctx
+func scopeFrom(ctx context.Context) *scope {
+	if ctx == nil {
+		return nil
+	}
+	if s, ok := ctx.Value(scopeKey{}).(*scope); ok {
+		return s
+	}
+	return nil
+}
+
+//Ioc bound to the scope of a process
+type scopedIoc struct {
+	*Ioc
+	scope 		*scope
+}
+
+func (si *scopedIoc) InsByName(name string) interface{} {
+	return si.Ioc.insByName(si.scope, name)
+}
+
+func (si *scopedIoc) InsByType(t reflect.Type) interface{} {
+	return si.Ioc.insByType(si.scope, t)
+}
+
+func (si *scopedIoc) InsByNameE(name string) (interface{}, error) {
+	return si.Ioc.insByNameE(si.scope, name)
+}
+
+func (si *scopedIoc) InsByTypeE(t reflect.Type) (interface{}, error) {
+	return si.Ioc.insByTypeE(si.scope, t)
+}
+
+func (si *scopedIoc) InsByNameCtx(ctx context.Context, name string) interface{} {
+	return si.Ioc.insByName(si.scopeOf(ctx), name)
+}
+
+func (si *scopedIoc) InsByTypeCtx(ctx context.Context, t reflect.Type) interface{} {
+	return si.Ioc.insByType(si.scopeOf(ctx), t)
+}
+
+func (si *scopedIoc) BeginRequest(ctx context.Context) (context.Context, func()) {
+	return si.Ioc.beginRequest(ctx, si.scopeOf(ctx))
+}
+
+func (si *scopedIoc) scopeOf(ctx context.Context) *scope {
+	if s := scopeFrom(ctx); s != nil {
+		return s
+	}
+	return si.scope
+}
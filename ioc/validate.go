@@ -0,0 +1,241 @@
+package ioc
+
+import (
+	"context"
+	"fmt"
+	"github.com/itea-tgl/itea-go/ioc/bean"
+	"github.com/itea-tgl/itea-go/process"
+	"reflect"
+	"strings"
+)
+
+//Problems found at once
+type Errors []error
+
+func (es Errors) Error() string {
+	var msg []string
+	for _, e := range es {
+		msg = append(msg, e.Error())
+	}
+	return strings.Join(msg, "\n")
+}
+
+//Rank of scope by lifetime, bean can only depend on beans living as long as it
+var scopeRank = map[string]int{
+	REQUEST: 1,
+	PROCESS: 2,
+	SINGLETON: 3,
+}
+
+type checkKey struct {
+	bean 		*bean.Bean
+	scope 		string
+}
+
+//Check beans without creating them
+type validator struct {
+	ioc 		*Ioc
+	checked 	map[checkKey]bool
+}
+
+//Check every registered bean and every process before any process starts,
+//all problems found are returned at once
+func (ioc *Ioc) Validate(processes []interface{}) error {
+	var errs Errors
+	classes := ioc.processClasses(processes)
+
+	v := &validator{
+		ioc: ioc,
+		checked: make(map[checkKey]bool),
+	}
+	for _, b := range ioc.beans() {
+		if classes[b.Name] {
+			continue
+		}
+		errs = append(errs, v.check(nil, b, false, "")...)
+	}
+
+	for _, p := range processes {
+		errs = append(errs, ioc.validateProcess(p.(*process.Process))...)
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+//Create all singletons except processes
+func (ioc *Ioc) Eager(processes []interface{}) error {
+	var errs Errors
+	classes := ioc.processClasses(processes)
+
+	for _, b := range ioc.beans() {
+		if b.Scope != SINGLETON || classes[b.Name] {
+			continue
+		}
+		if _, err := ioc.instance(nil, nil, b, false); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+//Names of process beans
+func (ioc *Ioc) processClasses(processes []interface{}) map[string]bool {
+	classes := make(map[string]bool)
+	for _, p := range ioc.register.process() {
+		classes[reflect.TypeOf(p).Name()] = true
+	}
+	for _, p := range processes {
+		classes[p.(*process.Process).Class] = true
+	}
+	return classes
+}
+
+//Check bean b asked from the top of path p, caller is the scope of the bean asking for it
+func (v *validator) check(p path, b *bean.Bean, ptr bool, caller string) []error {
+	scope := b.Scope
+	if scope == PROTOTYPE {
+		if scope = caller; strings.EqualFold(scope, "") {
+			scope = REQUEST
+		}
+	}
+	if scopeRank[scope] < scopeRank[caller] {
+		return []error{fmt.Errorf("bean [%s] of %s scope can not be wired into [%s] of %s scope", b.Name, b.Scope, p, caller)}
+	}
+
+	if i := p.find(b); i >= 0 {
+		if p.lazy(i, ptr) {
+			return nil
+		}
+		return []error{fmt.Errorf("circular dependency : %s", p.chain(b))}
+	}
+
+	key := checkKey{b, scope}
+	if v.checked[key] {
+		return nil
+	}
+	v.checked[key] = true
+	if isPostProcessor(b) && b.Scope != SINGLETON {
+		return []error{fmt.Errorf("post processor [%s] should be singleton", b.Name)}
+	}
+
+	p, _ = p.push(b, ptr)
+
+	var errs []error
+	if f := b.GetFactory(); f.IsValid() {
+		ft := f.Type()
+		for i := 0; i < ft.NumIn(); i++ {
+			wf, ok := paramOf(ft, i)
+			if !ok {
+				errs = append(errs, fmt.Errorf("param %d(%s) of factory of [%s] can not be wired", i, ft.In(i).String(), p))
+				continue
+			}
+			errs = append(errs, v.checkWired(p, wf, scope, true)...)
+		}
+		return errs
+	}
+
+	t := b.GetConcreteType()
+	for index := 0; index < t.NumField(); index++ {
+		sf := t.Field(index)
+		if !strings.EqualFold(sf.PkgPath, "") {
+			continue
+		}
+		if wf, ok := wiredOf(sf); ok {
+			errs = append(errs, v.checkWired(p, wf, scope, false)...)
+		}
+		if err := v.ioc.value(p, sf, reflect.New(sf.Type).Elem()); err != nil {
+			errs = append(errs, err)
+		}
+		if err := checkRefresh(p, sf); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return append(errs, checkHooks(p, t)...)
+}
+
+//Check wired field of the bean on top of p, which lives in scope
+func (v *validator) checkWired(p path, wf *wiredField, scope string, required bool) []error {
+	if wf.slice {
+		var errs []error
+		for _, dep := range v.ioc.wiredBeans(wf) {
+			errs = append(errs, v.check(p, dep, true, scope)...)
+		}
+		return errs
+	}
+	dep, err := v.ioc.wiredBean(p, wf)
+	switch {
+	case err != nil:
+		return []error{err}
+	case dep == nil && required:
+		return []error{fmt.Errorf("%s(%s) of [%s] is not registed", wf.field.Name, wf.field.Type.String(), p)}
+	case dep == nil:
+		return nil
+	case !wf.accept(dep):
+		return []error{fmt.Errorf("can not wire %s(%s) of [%s] with bean [%s]", wf.field.Name, wf.field.Type.String(), p, dep.Name)}
+	}
+	return v.check(p, dep, wf.ptr, scope)
+}
+
+//Check signatures of construct and init method
+func checkHooks(p path, t reflect.Type) []error {
+	var errs []error
+	pt := reflect.PtrTo(t)
+	if m, ok := pt.MethodByName(CONSTRUCT_FUNC); ok {
+		if m.Type.NumIn() > 1 {
+			errs = append(errs, fmt.Errorf("method %s of [%s] should have no params", CONSTRUCT_FUNC, p))
+		}
+		if !hookResult(m.Type) {
+			errs = append(errs, fmt.Errorf("method %s of [%s] should return nothing or error", CONSTRUCT_FUNC, p))
+		}
+	}
+	if m, ok := pt.MethodByName(INIT_FUNC); ok {
+		if n := m.Type.NumIn(); n > 2 || (n == 2 && !pt.AssignableTo(m.Type.In(1))) {
+			errs = append(errs, fmt.Errorf("method %s of [%s] should have no params or accept the instance", INIT_FUNC, p))
+		}
+		if !hookResult(m.Type) {
+			errs = append(errs, fmt.Errorf("method %s of [%s] should return nothing or error", INIT_FUNC, p))
+		}
+	}
+	return errs
+}
+
+//Whether hook of type ht returns nothing or error
+func hookResult(ht reflect.Type) bool {
+	return ht.NumOut() == 0 || (ht.NumOut() == 1 && ht.Out(0) == errorType)
+}
+
+//Create process with its params and check it by its method `Validate() []error`
+func (ioc *Ioc) validateProcess(process *process.Process) []error {
+	if strings.EqualFold(process.Class, "") {
+		return []error{fmt.Errorf("process [%s] has no class", process.Name)}
+	}
+
+	s := newScope(PROCESS, nil)
+	defer ioc.release(s)
+	p, err := ioc.newProcess(context.WithValue(ioc.ctx, scopeKey{}, s), process, s)
+	if err != nil {
+		return []error{err}
+	}
+
+	var errs []error
+	if exec := process.ExecuteMethod; !strings.EqualFold(exec, "") && !p.MethodByName(exec).IsValid() {
+		errs = append(errs, fmt.Errorf("process [%s] has no method [%s]", process.Name, exec))
+	}
+
+	if m := p.MethodByName(VALIDATE_FUNC); m.IsValid() && m.Type().NumIn() == 0 && m.Type().NumOut() == 1 {
+		if list, ok := m.Call(nil)[0].Interface().([]error); ok {
+			for _, e := range list {
+				errs = append(errs, fmt.Errorf("process [%s] : %s", process.Name, e))
+			}
+		}
+	}
+	return errs
+}
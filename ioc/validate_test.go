@@ -0,0 +1,57 @@
+package ioc
+
+import (
+	"context"
+	"github.com/itea-tgl/itea-go/ioc/bean"
+	"github.com/itea-tgl/itea-go/ioc/iface"
+	"github.com/itea-tgl/itea-go/process"
+	"sync/atomic"
+	"testing"
+)
+
+var closed int32
+
+//Bean of process scope closed with its process
+type processConn struct{}
+
+func (pc *processConn) Close() error {
+	atomic.AddInt32(&closed, 1)
+	return nil
+}
+
+//Process creating its bean while validating
+type validProcess struct {
+	Ioc 		iface.IIoc
+}
+
+func (vp *validProcess) Validate() []error {
+	if _, err := vp.Ioc.InsByNameE("processConn"); err != nil {
+		return []error{err}
+	}
+	return nil
+}
+
+func TestValidateReleasesProcess(t *testing.T) {
+	atomic.StoreInt32(&closed, 0)
+	ioc := NewIoc(context.Background())
+	ioc.Register([]interface{}{validProcess{}})
+	ioc.RegisterBeans([]*bean.Bean{{Scope: PROCESS, Concrete: processConn{}}})
+
+	if err := ioc.Validate([]interface{}{&process.Process{Name: "p", Class: "validProcess"}}); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&closed); n != 1 {
+		t.Fatalf("process bean closed %d times after validation, want 1", n)
+	}
+}
+
+func TestProcessWithoutClass(t *testing.T) {
+	ioc := NewIoc(context.Background())
+	p := &process.Process{Name: "p"}
+	if err := ioc.Validate([]interface{}{p}); err == nil {
+		t.Error("validation should fail for process without class")
+	}
+	if err := ioc.ExecProcess(context.Background(), p); err == nil {
+		t.Error("executing process without class should fail")
+	}
+}
@@ -0,0 +1,74 @@
+package ioc
+
+import (
+	"fmt"
+	"github.com/itea-tgl/itea-go/system"
+	"reflect"
+	"strings"
+)
+
+const VALUE_REQUIRED = "required"
+
+//Config injected into field, tagged like `value:"key"`, `value:"key:default"` or `value:"key,required"`
+type valueTag struct {
+	key 		string
+	def 		string
+	hasDef 		bool
+	required 	bool
+}
+
+func parseValueTag(tag string) valueTag {
+	var vt valueTag
+	for {
+		i := strings.LastIndex(tag, ",")
+		if i < 0 || !strings.EqualFold(strings.TrimSpace(tag[i+1:]), VALUE_REQUIRED) {
+			break
+		}
+		vt.required = true
+		tag = tag[:i]
+	}
+	if i := strings.Index(tag, ":"); i >= 0 {
+		vt.key, vt.def, vt.hasDef = tag[:i], tag[i+1:], true
+	} else {
+		vt.key = tag
+	}
+	vt.key = strings.TrimSpace(vt.key)
+	return vt
+}
+
+//Inject field tagged by `value` with config, field of Value is stored atomically
+func (ioc *Ioc) value(p path, sf reflect.StructField, f reflect.Value) error {
+	tag := sf.Tag.Get(VALUE_TAG)
+	if strings.EqualFold(tag, "") {
+		return nil
+	}
+	vt := parseValueTag(tag)
+
+	t, set := f.Type(), f.Set
+	if h, ok := f.Addr().Interface().(holder); ok {
+		t, set = h.valueType(), h.store
+	}
+
+	if system.Conf.Exists(vt.key) {
+		v := reflect.New(t)
+		if err := system.Conf.Decode(vt.key, v.Interface()); err != nil {
+			return fmt.Errorf("value of %s in [%s] : %s", sf.Name, p, err)
+		}
+		set(v.Elem())
+		return nil
+	}
+
+	if vt.hasDef {
+		v, err := system.Convert(vt.def, t)
+		if err != nil {
+			return fmt.Errorf("default value of %s in [%s] : %s", sf.Name, p, err)
+		}
+		set(v)
+		return nil
+	}
+
+	if vt.required {
+		return fmt.Errorf("config [%s] is required by %s in [%s]", vt.key, sf.Name, p)
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package ioc
+
+import (
+	"fmt"
+	"github.com/itea-tgl/itea-go/ioc/bean"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+//Field tagged by `wired`
+type wiredField struct {
+	field 		reflect.StructField
+	name 		string			//Name of bean, empty to get bean by type
+	typ 		reflect.Type	//Type of bean
+	ptr 		bool			//Field holds a pointer or an interface
+	slice 		bool			//Field holds all beans implementing interface typ
+}
+
+//Get wired field of sf.
+//Empty or "true" tag asks for the bean of field type, other tag asks for the bean of that name.
+//Slice of non-empty interface asks for all beans implementing it
+func wiredOf(sf reflect.StructField) (*wiredField, bool) {
+	tag, ok := sf.Tag.Lookup(WIRED_TAG)
+	if !ok {
+		return nil, false
+	}
+	wf := &wiredField{field: sf, ptr: true}
+	switch sf.Type.Kind() {
+	case reflect.Struct:
+		wf.typ, wf.ptr = sf.Type, false
+	case reflect.Ptr:
+		wf.typ = sf.Type.Elem()
+	case reflect.Interface:
+		wf.typ = sf.Type
+	case reflect.Slice:
+		if sf.Type.Elem().Kind() != reflect.Interface || sf.Type.Elem().NumMethod() == 0 {
+			return nil, false
+		}
+		wf.typ, wf.slice = sf.Type.Elem(), true
+		return wf, true
+	default:
+		return nil, false
+	}
+	if !strings.EqualFold(tag, "") && !strings.EqualFold(tag, "true") {
+		wf.name = tag
+	}
+	return wf, true
+}
+
+//Whether instance of b can be set into the field
+func (wf *wiredField) accept(b *bean.Bean) bool {
+	t := b.InstanceType()
+	if !wf.ptr {
+		if t.Kind() != reflect.Ptr {
+			return false
+		}
+		t = t.Elem()
+	}
+	return t.AssignableTo(wf.field.Type)
+}
+
+//Get bean asked by wired field, p is the path of the bean holding it
+func (ioc *Ioc) wiredBean(p path, wf *wiredField) (*bean.Bean, error) {
+	if !strings.EqualFold(wf.name, "") {
+		b, ok := ioc.beanByName(wf.name)
+		if !ok {
+			return nil, fmt.Errorf("can not find bean [%s], required by [%s]", wf.name, p)
+		}
+		return b, nil
+	}
+	b, err := ioc.beanByType(wf.typ)
+	if err != nil {
+		return nil, fmt.Errorf("%s, required by [%s]", err, p)
+	}
+	return b, nil
+}
+
+//Get beans implementing interface of wired slice field, sorted by name
+func (ioc *Ioc) wiredBeans(wf *wiredField) []*bean.Bean {
+	var list []*bean.Bean
+	for _, b := range ioc.beans() {
+		if b.InstanceType().Implements(wf.typ) {
+			list = append(list, b)
+		}
+	}
+	return list
+}
+
+//Inject field tagged by `wired`
+func (ioc *Ioc) wire(p path, s *scope, sf reflect.StructField, f reflect.Value) error {
+	wf, ok := wiredOf(sf)
+	if !ok {
+		return nil
+	}
+	v, err := ioc.wiredValue(p, s, wf)
+	if err != nil || !v.IsValid() {
+		return err
+	}
+	f.Set(v)
+	return nil
+}
+
+//Get value for wired field, invalid if nothing to wire
+func (ioc *Ioc) wiredValue(p path, s *scope, wf *wiredField) (reflect.Value, error) {
+	if wf.slice {
+		return ioc.sliceValue(p, s, wf)
+	}
+	b, err := ioc.wiredBean(p, wf)
+	if err != nil || b == nil {
+		return reflect.Value{}, err
+	}
+	i, err := ioc.instance(p, s, b, wf.ptr)
+	if err != nil || i == nil {
+		return reflect.Value{}, err
+	}
+
+	v := reflect.ValueOf(i)
+	if !wf.ptr {
+		v = reflect.Indirect(v)
+	}
+	if !v.Type().AssignableTo(wf.field.Type) {
+		return reflect.Value{}, fmt.Errorf("can not wire %s(%s) of [%s] with %s", wf.field.Name, wf.field.Type.String(), p, v.Type().String())
+	}
+	return v, nil
+}
+
+//Get value for wired slice field, instances are sorted by their method `Order() int`
+//or Order of their bean, then by bean name
+func (ioc *Ioc) sliceValue(p path, s *scope, wf *wiredField) (reflect.Value, error) {
+	beans := ioc.wiredBeans(wf)
+	if len(beans) == 0 {
+		return reflect.Value{}, nil
+	}
+	type item struct {
+		order 	int
+		v 		reflect.Value
+	}
+	items := make([]item, 0, len(beans))
+	for _, b := range beans {
+		i, err := ioc.instance(p, s, b, true)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if i == nil {
+			continue
+		}
+		items = append(items, item{orderOf(b, i), reflect.ValueOf(i)})
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].order < items[j].order
+	})
+
+	v := reflect.MakeSlice(wf.field.Type, len(items), len(items))
+	for i, it := range items {
+		v.Index(i).Set(it.v)
+	}
+	return v, nil
+}
+
+//Order of instance i of bean b
+func orderOf(b *bean.Bean, i interface{}) int {
+	m := reflect.ValueOf(i).MethodByName(ORDER_FUNC)
+	if m.IsValid() && m.Type().NumIn() == 0 && m.Type().NumOut() == 1 && m.Type().Out(0).Kind() == reflect.Int {
+		return int(m.Call(nil)[0].Int())
+	}
+	return b.Order
+}
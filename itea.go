@@ -2,6 +2,7 @@ package itea
 
 import (
 	"context"
+	"fmt"
 	"github.com/itea-tgl/itea-go/ilog"
 	"github.com/itea-tgl/itea-go/ioc"
 	"github.com/itea-tgl/itea-go/ioc/bean"
@@ -9,8 +10,12 @@ import (
 	"github.com/itea-tgl/itea-go/process"
 	"github.com/itea-tgl/itea-go/signal"
 	"github.com/itea-tgl/itea-go/constant"
+	"io/ioutil"
 	"os"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 var (
@@ -20,6 +25,10 @@ var (
 	config			*system.Config
 )
 
+func init() {
+	system.RegisterSchema("application.process.*", process.Process{})
+}
+
 type Itea struct {
 	process			[]interface{}
 	ioc 			*ioc.Ioc
@@ -30,10 +39,12 @@ func New(appConfig string, debug bool) *Itea {
 	system.InitConf(appConfig)
 	ctx = context.WithValue(context.Background(), constant.DEBUG, debug)
 	system.InitLog()
-	return &Itea{
+	i := &Itea{
 		process: system.Conf.GetStructArray("application.process", process.Process{}),
 		ioc: ioc.NewIoc(ctx),
 	}
+	system.Conf.Subscribe(i.ioc.Refresh)
+	return i
 }
 
 //Register simple beans
@@ -70,17 +81,38 @@ func (i *Itea) Run() {
 		break
 	case system.Help:
 		break
+	case !strings.EqualFold(system.Graph, ""):
+		i.graph(system.Graph)
+		break
+	case system.Encrypt:
+		i.encrypt()
+		break
 	case system.Start:
 		i.start()
 		break
 	}
 }
 
+//Reload config, fields tagged by `refresh:"true"` of singletons are injected again
+func (i *Itea) Reload() error {
+	return system.Conf.Reload()
+}
+
 //Start Itea
 func (i *Itea) start() {
 	if i.process == nil {
 		panic("Can not find config of process or process is nil")
 	}
+
+	//Check beans and processes before any process starts
+	if err := i.ioc.Validate(i.process); err != nil {
+		i.exit(fmt.Errorf("Itea validate fail :\n%s", err))
+	}
+	if system.Conf.GetBoolean(fmt.Sprintf("%s.%s", system.Conf.FileName, constant.EAGER_KEY)) {
+		if err := i.ioc.Eager(i.process); err != nil {
+			i.exit(fmt.Errorf("Itea create beans fail :\n%s", err))
+		}
+	}
 	
 	signal.LogProcessInfo()
 
@@ -88,10 +120,19 @@ func (i *Itea) start() {
 	defer close(s)
 
 	sigs = make(chan os.Signal)
-	go signal.ProcessSignal(sigs, s)
+	go signal.ProcessSignal(sigs, s, func() {
+		if err := i.Reload(); err != nil {
+			ilog.Error(err)
+		}
+	})
 
 	ctx, stop := context.WithCancel(ctx)
 
+	//Reload config when its files change, besides SIGUSR1
+	if d := system.Conf.WatchInterval(); d > 0 {
+		go system.Conf.Watch(ctx, d)
+	}
+
 	go func() {
 		if <-s {
 			ilog.Info("Itea stop ...")
@@ -99,25 +140,71 @@ func (i *Itea) start() {
 		}
 	}()
 
+	//A process failing to start stops the others, Itea exits with code 1
+	var failed int32
 	var wg sync.WaitGroup
 	for _, p := range i.process {
 		var process = p.(*process.Process)
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			i.ioc.ExecProcess(ctx, process)
+			if err := i.ioc.ExecProcess(ctx, process); err != nil {
+				ilog.Error(err)
+				atomic.StoreInt32(&failed, 1)
+				stop()
+			}
 		}()
 	}
 	wg.Wait()
 
-	ilog.Info("Itea stop success. Good bye ")
+	//Destroy beans after all processes stop
+	var timeout time.Duration
+	system.Conf.Decode(fmt.Sprintf("%s.%s", system.Conf.FileName, constant.DESTROY_TIMEOUT_KEY), &timeout)
+	i.ioc.Destroy(timeout)
+
+	code := int(atomic.LoadInt32(&failed))
+	if code == 0 {
+		ilog.Info("Itea stop success. Good bye ")
+	} else {
+		ilog.Error("Itea stop with failed process")
+	}
 	
 	if ilog.Done() {
 		close(sigs)
 		signal.RemovePid()
-		os.Exit(0)
+		os.Exit(code)
+	}
+
+}
+
+//Print bean graph in format
+func (i *Itea) graph(format string) {
+	out, err := i.ioc.Graph().Export(format)
+	if err != nil {
+		i.exit(err)
 	}
+	os.Stdout.Write(out)
+}
+
+//Print value read from stdin encrypted by config key, for values like ENC(...) in config.
+//Value is kept off the command line so that it is not seen in process list or shell history
+func (i *Itea) encrypt() {
+	value, err := ioutil.ReadAll(os.Stdin)
+	if err != nil {
+		i.exit(err)
+	}
+	out, err := system.EncryptValue(strings.TrimRight(string(value), "\r\n"))
+	if err != nil {
+		i.exit(err)
+	}
+	fmt.Println(out)
+}
 
+//Exit itea with error before processes start
+func (i *Itea) exit(err error) {
+	ilog.Error(err)
+	ilog.Done()
+	os.Exit(1)
 }
 
 //Stop itea
@@ -146,3 +233,37 @@ func NewIteaTest(appConfig string, debug bool) *IteaTest {
 func (itea *IteaTest) Instance(i interface{}) interface{} {
 	return itea.Ioc.InsByClass(i)
 }
+
+//Create test sharing registered beans, with its own singletons and overrides.
+//Use it for each test running in parallel
+func (itea *IteaTest) Child() *IteaTest {
+	return &IteaTest{
+		Ioc: itea.Ioc.Child(),
+	}
+}
+
+//Register beans
+func (itea *IteaTest) Register(beans ...interface{}) *IteaTest {
+	itea.Ioc.Register(beans)
+	return itea
+}
+
+//Replace bean of name with v, v is an instance, a struct value taken as instance or a factory
+func (itea *IteaTest) Override(name string, v interface{}) error {
+	return itea.Ioc.OverrideByName(name, v)
+}
+
+//Replace bean wired into fields of type of class with v
+func (itea *IteaTest) OverrideType(class interface{}, v interface{}) error {
+	return itea.Ioc.OverrideByType(class, v)
+}
+
+//Destroy created singletons, they are created again on next lookup
+func (itea *IteaTest) Reset() {
+	itea.Ioc.Reset()
+}
+
+//Create Instance, or the error why it can not be created
+func (itea *IteaTest) InstanceE(i interface{}) (interface{}, error) {
+	return itea.Ioc.InsByClassE(i)
+}
@@ -22,9 +22,10 @@ type Scheduler struct {
 	cron			*cron.Cron
 }
 
-func (s *Scheduler) Execute() {
+//Scheduler start, error is returned if a task can not be created
+func (s *Scheduler) Execute() error {
 	if len(s.Processor) == 0 {
-		return
+		return nil
 	}
 
 	s.cron = cron.New()
@@ -46,15 +47,15 @@ func (s *Scheduler) Execute() {
 
 		name := p[TASK_KEY].(string)
 
-		task := reflect.ValueOf(s.Ioc.InsByName(name))
-		if !task.IsValid() {
-			ilog.Error(fmt.Sprintf("task [%s] is nil, please check out if [%s] is registed", name, name))
-			continue
+		ins, err := s.Ioc.InsByNameE(name)
+		if err != nil {
+			return fmt.Errorf("task [%s] : %s", name, err)
 		}
+		task := reflect.ValueOf(ins)
 		
 		method := task.MethodByName("Execute")
 		if !method.IsValid() {
-			ilog.Error(fmt.Sprintf("task [%s] need the method of `Execute`", name, name))
+			ilog.Error(fmt.Sprintf("task [%s] need the method of `Execute`", name))
 			continue
 		}
 		
@@ -68,6 +69,37 @@ func (s *Scheduler) Execute() {
 	ilog.Info("=== 【Scheduler】 Start ===")
 
 	s.stop()
+	return nil
+}
+
+//Check tasks and cron specs
+func (s *Scheduler) Validate() []error {
+	var errs []error
+	for i, process := range s.Processor {
+		p, ok := process.(map[interface{}]interface{})
+		if !ok {
+			errs = append(errs, fmt.Errorf("processor [%d] should be a map of %s and %s", i, TASK_KEY, CRON_KEY))
+			continue
+		}
+		name, _ := p[TASK_KEY].(string)
+		spec, _ := p[CRON_KEY].(string)
+		if name == "" || spec == "" {
+			errs = append(errs, fmt.Errorf("processor [%d] need %s and %s", i, TASK_KEY, CRON_KEY))
+			continue
+		}
+		if _, err := cron.Parse(spec); err != nil {
+			errs = append(errs, fmt.Errorf("cron [%s] of task [%s] : %s", spec, name, err))
+		}
+		b := s.Ioc.BeansByName(name)
+		if b == nil {
+			errs = append(errs, fmt.Errorf("task [%s] is not registed", name))
+			continue
+		}
+		if m, ok := b.Method("Execute"); !ok || m.NumIn() != 0 {
+			errs = append(errs, fmt.Errorf("task [%s] need the method of `Execute` without params", name))
+		}
+	}
+	return errs
 }
 
 //Scheduler stop
@@ -2,6 +2,7 @@ package ihttp
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/itea-tgl/itea-go/ilog"
 	"github.com/itea-tgl/itea-go/ioc/iface"
@@ -10,6 +11,7 @@ import (
 	"io"
 	"net/http"
 	"reflect"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -33,6 +35,9 @@ type routeAction struct {
 	exec reflect.Value
 	method string
 	interceptor []IInterceptor
+	controller string
+	action string
+	perRequest bool
 }
 
 type HttpServer struct {
@@ -49,8 +54,8 @@ type HttpServer struct {
 	wg 				sync.WaitGroup
 }
 
-//Http server init
-func (hs *HttpServer) Execute() {
+//Http server init, error is returned if a controller or an interceptor can not be created
+func (hs *HttpServer) Execute() error {
 
 	//Create http server
 	hs.ser = &http.Server{
@@ -64,6 +69,14 @@ func (hs *HttpServer) Execute() {
 	//Create route manager
 	mux := http.NewServeMux()
 
+	var errs []string
+	var mutex sync.Mutex
+	fail := func(err error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		errs = append(errs, err.Error())
+	}
+
 	for p, as := range hs.Router.Actions {
 		hs.wg.Add(1)
 		go func(path string, actions []*action) {
@@ -71,19 +84,42 @@ func (hs *HttpServer) Execute() {
 			
 			var routeActions []routeAction
 			for _, a := range actions {
-				exec := hs.extractExec(a)
+				//Controller of request scope is resolved for each request
+				perRequest := false
+				if b := hs.Ioc.BeansByName(a.Controller); b != nil && b.PerRequest() {
+					perRequest = true
+				}
 
-				if exec == reflect.ValueOf(nil) {
-					continue
+				var exec reflect.Value
+				if perRequest {
+					if !hs.hasMethod(a) {
+						continue
+					}
+				} else {
+					var err error
+					if exec, err = hs.extractExec(a); err != nil {
+						fail(err)
+						return
+					}
+					if exec == reflect.ValueOf(nil) {
+						continue
+					}
 				}
 				
 				//Get action interceptor list
-				interceptor := ActionInterceptor(a.Middleware, hs.Ioc)
+				interceptor, err := actionInterceptor(a.Middleware, hs.Ioc)
+				if err != nil {
+					fail(err)
+					return
+				}
 				
 				routeActions = append(routeActions, routeAction{
 					exec: exec,
 					method: a.Method,
 					interceptor: interceptor,
+					controller: a.Controller,
+					action: a.Action,
+					perRequest: perRequest,
 				})
 			}
 
@@ -92,10 +128,15 @@ func (hs *HttpServer) Execute() {
 	}
 
 	hs.wg.Wait()
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return errors.New(strings.Join(errs, "\n"))
+	}
 
 	hs.ser.Handler = mux
 	//Start http server
 	hs.start()
+	return nil
 }
 
 //Http handler
@@ -105,6 +146,11 @@ func (hs *HttpServer) handler(routeActions []routeAction) func(w http.ResponseWr
 		
 		hs.wg.Add(1)
 
+		//Beans of request scope live until the request finishes
+		ctx, release := hs.Ioc.BeginRequest(r.Context())
+		defer release()
+		r = r.WithContext(ctx)
+
 		response := &Response{
 			Header: make(map[string]string),
 		}
@@ -123,6 +169,13 @@ func (hs *HttpServer) handler(routeActions []routeAction) func(w http.ResponseWr
 		for _, ra := range routeActions {
 			if strings.EqualFold(ra.method, r.Method) {
 				exec, interceptor = ra.exec, ra.interceptor
+				if ra.perRequest {
+					exec = hs.requestExec(ctx, ra)
+					if !exec.IsValid() {
+						response.Data = "Controller not available"
+						return
+					}
+				}
 				break
 			}
 		}
@@ -184,22 +237,91 @@ func (hs *HttpServer) handler(routeActions []routeAction) func(w http.ResponseWr
 	}
 }
 
-func (hs *HttpServer) extractExec(a *action) reflect.Value {
-	c := reflect.ValueOf(hs.Ioc.InsByName(a.Controller))
-	if !c.IsValid() {
-		ilog.Error(fmt.Sprintf("controller [%s] is nil, please check out if [%s] is registed", a.Controller, a.Controller))
-		//panic(fmt.Sprintf("Controller [%s] need register", a.Controller))
-		return reflect.ValueOf(nil)
+//Get action of controller, error is returned if the controller can not be created
+func (hs *HttpServer) extractExec(a *action) (reflect.Value, error) {
+	ins, err := hs.Ioc.InsByNameE(a.Controller)
+	if err != nil {
+		return reflect.ValueOf(nil), fmt.Errorf("controller [%s] of [%s] : %s", a.Controller, a.Uri, err)
 	}
-	m := c.MethodByName(a.Action)
+	m := reflect.ValueOf(ins).MethodByName(a.Action)
 
 	if !m.IsValid() {
 		ilog.Error(fmt.Sprintf("can not find method [%s] in [%s]", a.Action, a.Controller))
-		return reflect.ValueOf(nil)
+		return reflect.ValueOf(nil), nil
 		//panic(fmt.Sprintf("Can not find method [%s] in [%s]", a.Action, a.Controller))
 	}
 
-	return m
+	return m, nil
+}
+
+//Check method of controller which is resolved for each request
+func (hs *HttpServer) hasMethod(a *action) bool {
+	if _, ok := hs.Ioc.BeansByName(a.Controller).Method(a.Action); !ok {
+		ilog.Error(fmt.Sprintf("can not find method [%s] in [%s]", a.Action, a.Controller))
+		return false
+	}
+	return true
+}
+
+//Resolve controller of request scope and get its action
+func (hs *HttpServer) requestExec(ctx context.Context, ra routeAction) reflect.Value {
+	c := reflect.ValueOf(hs.Ioc.InsByNameCtx(ctx, ra.controller))
+	if !c.IsValid() {
+		ilog.Error(fmt.Sprintf("controller [%s] is nil, please check out if [%s] is registed", ra.controller, ra.controller))
+		return reflect.ValueOf(nil)
+	}
+	return c.MethodByName(ra.action)
+}
+
+//Check controllers, actions and interceptors of routes
+func (hs *HttpServer) Validate() []error {
+	var router Route
+	if err := router.Load(hs.Route, system.Env); err != nil {
+		return []error{err}
+	}
+	var uris []string
+	for uri := range router.Actions {
+		uris = append(uris, uri)
+	}
+	sort.Strings(uris)
+
+	var errs []error
+	for _, uri := range uris {
+		for _, a := range router.Actions[uri] {
+			errs = append(errs, hs.checkAction(a)...)
+		}
+	}
+	return errs
+}
+
+func (hs *HttpServer) checkAction(a *action) []error {
+	var errs []error
+	if b := hs.Ioc.BeansByName(a.Controller); b == nil {
+		errs = append(errs, fmt.Errorf("controller [%s] of [%s] is not registed", a.Controller, a.Uri))
+	} else if m, ok := b.Method(a.Action); !ok {
+		errs = append(errs, fmt.Errorf("can not find method [%s] in [%s]", a.Action, a.Controller))
+	} else if err := checkActionType(m); err != nil {
+		errs = append(errs, fmt.Errorf("action [%s@%s] of [%s] : %s", a.Controller, a.Action, a.Uri, err))
+	}
+	return append(errs, CheckInterceptor(a.Middleware, hs.Ioc)...)
+}
+
+//Check signature of action method
+func checkActionType(t reflect.Type) error {
+	n := t.NumIn()
+	rType, wType := reflect.TypeOf(&http.Request{}), reflect.TypeOf(new(http.ResponseWriter)).Elem()
+	if n > 2 ||
+		(n >= 1 && !rType.AssignableTo(t.In(0))) ||
+		(n == 2 && (t.In(1).Kind() != reflect.Interface || !wType.Implements(t.In(1)))) {
+		return errors.New("params must be (*http.Request) or (*http.Request, http.ResponseWriter)")
+	}
+	if t.NumOut() > 2 {
+		return errors.New("invalid num of return, 2 or less is accepted")
+	}
+	if t.NumOut() == 2 && !t.Out(1).Implements(reflect.TypeOf(new(error)).Elem()) {
+		return errors.New("invalid type of the second return param, error accepted")
+	}
+	return nil
 }
 
 //Http server start
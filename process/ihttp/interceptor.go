@@ -13,6 +13,15 @@ type IInterceptor interface {
 }
 
 func ActionInterceptor(interceptors []string, ioc iface.IIoc) []IInterceptor {
+	list, err := actionInterceptor(interceptors, ioc)
+	if err != nil {
+		ilog.Error(err)
+	}
+	return list
+}
+
+//Get interceptors of action, error is returned if one of them can not be created
+func actionInterceptor(interceptors []string, ioc iface.IIoc) ([]IInterceptor, error) {
 	var list []IInterceptor
 	IType := reflect.TypeOf(new(IInterceptor)).Elem()
 	l := len(interceptors)
@@ -25,16 +34,32 @@ func ActionInterceptor(interceptors []string, ioc iface.IIoc) []IInterceptor {
 			continue
 		}
 		t = b.GetConcreteType()
-		if !t.Implements(IType) {
+		if !b.InstanceType().Implements(IType) {
 			ilog.Error(fmt.Sprintf("interceptor [%s] is not impliment of ihttp.IInterceptor", name))
 			continue
 		}
-		ins := ioc.InsByType(t)
-		if ins == nil {
-			ilog.Error(fmt.Sprintf("interceptor [%s] is nil, please check out if [%s] is registed", name, name))
-			continue
+		ins, err := ioc.InsByTypeE(t)
+		if err != nil {
+			return list, fmt.Errorf("interceptor [%s] : %s", name, err)
 		}
 		list = append(list, ins.(IInterceptor))
 	}
-	return list
-}
\ No newline at end of file
+	return list, nil
+}
+
+//Check interceptors are registed and implement IInterceptor
+func CheckInterceptor(interceptors []string, ioc iface.IIoc) []error {
+	var errs []error
+	IType := reflect.TypeOf(new(IInterceptor)).Elem()
+	for _, name := range interceptors {
+		b := ioc.BeansByName(name)
+		if b == nil {
+			errs = append(errs, fmt.Errorf("can not find beans of [%s]", name))
+			continue
+		}
+		if !b.InstanceType().Implements(IType) {
+			errs = append(errs, fmt.Errorf("interceptor [%s] is not impliment of ihttp.IInterceptor", name))
+		}
+	}
+	return errs
+}
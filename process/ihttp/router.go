@@ -1,11 +1,12 @@
 package ihttp
 
 import (
+	"errors"
+	"fmt"
 	"github.com/itea-tgl/itea-go/constant"
 	"github.com/itea-tgl/itea-go/system"
-	"gopkg.in/yaml.v2"
-	"io/ioutil"
 	"os"
+	"reflect"
 	"regexp"
 	"strings"
 )
@@ -42,24 +43,35 @@ type action struct {
 }
 
 func (r *Route) InitRoute(routeConfig string, env string) {
+	if err := r.Load(routeConfig, env); err != nil {
+		panic(err.Error())
+	}
+}
+
+//Load route config
+func (r *Route) Load(routeConfig string, env string) error {
 	projectPath, err := os.Getwd()
 	if err != nil {
-		panic(err)
+		return err
+	}
+	data, err := system.DecodeFile(projectPath + strings.Replace(routeConfig, constant.SEARCH_ENV, env, -1))
+	if os.IsNotExist(err) {
+		return errors.New("Route config not find")
 	}
-	data, err := ioutil.ReadFile(projectPath + strings.Replace(routeConfig, constant.SEARCH_ENV, env, -1))
 	if err != nil {
-		panic("Route config not find")
+		return fmt.Errorf("Route config extract fail : %s", err)
 	}
-	var routeConf routeConf
-	err = yaml.Unmarshal(data, &routeConf)
+	rc, err := system.Convert(data, reflect.TypeOf(routeConf{}))
 	if err != nil {
-		panic("Route config extract fail")
+		return fmt.Errorf("Route config extract fail : %s", err)
 	}
+	routeConf := rc.Interface().(routeConf)
 	r.Groups = make(map[string]groupConf)
 	for _, gConf := range routeConf.Groups {
 		r.Groups[gConf.Name] = gConf
 	}
 	r.Actions = extract(routeConf.ActionConf, r.Groups)
+	return nil
 }
 
 func extract(ac map[string]actionConf, groups map[string]groupConf) map[string][]*action{
@@ -2,12 +2,14 @@ package kafka
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/Shopify/sarama"
 	cluster "github.com/bsm/sarama-cluster"
 	"github.com/itea-tgl/itea-go/constant"
 	"github.com/itea-tgl/itea-go/ilog"
 	"github.com/itea-tgl/itea-go/ioc/iface"
+	"reflect"
 	"strings"
 )
 
@@ -25,11 +27,18 @@ type KafkaConsumer struct {
 	Group			string
 	Processor		[]interface{}
 	consumer		*cluster.Consumer
-	handler			map[string][]IHandler
+	handler			map[string][]*handlerRef
 	debug 			bool
 }
 
-func (kc *KafkaConsumer) Execute() {
+//Handler of message, handler of request scope is resolved for each message
+type handlerRef struct {
+	name 			string
+	handler 		IHandler
+}
+
+//Kafka consumer start, error is returned if a handler can not be created
+func (kc *KafkaConsumer) Execute() error {
 
 	if d, ok := kc.Ctx.Value(constant.DEBUG).(bool); ok {
 		kc.debug = d
@@ -76,17 +85,23 @@ func (kc *KafkaConsumer) Execute() {
 		}
 	}()
 
-	kc.initHandler()
+	if err := kc.initHandler(); err != nil {
+		kc.consumer.Close()
+		return err
+	}
 	ilog.Info(fmt.Sprintf("=== 【Kafka】Consumer [%s] start [Topic : %s, Group : %s] ===", kc.Name, kc.Topic, kc.Group))
 	kc.start()
+	return nil
 }
 
-func (kc *KafkaConsumer) initHandler() {
-	kc.handler = map[string][]IHandler{}
+func (kc *KafkaConsumer) initHandler() error {
+	kc.handler = map[string][]*handlerRef{}
 	for _, v := range kc.Processor {
 		
 		if i, ok := v.(string); ok {
-			kc.appendHandler("", i)
+			if err := kc.appendHandler("", i); err != nil {
+				return err
+			}
 			continue
 		}
 
@@ -95,28 +110,74 @@ func (kc *KafkaConsumer) initHandler() {
 			if _, ok := i[KEY_KEY]; ok {
 				k = i[KEY_KEY].(string)
 			}
-			kc.appendHandler(k, i[HANDLER_KEY].(string))
+			if err := kc.appendHandler(k, i[HANDLER_KEY].(string)); err != nil {
+				return err
+			}
 		}
 
 	}
+	return nil
 }
 
-func (kc *KafkaConsumer) appendHandler(k string, i string) {
-	h := kc.Ioc.InsByName(i)
-	if h == nil {
-		ilog.Error(fmt.Sprintf("consumer [%s] is nil, please check out if [%s] is registed", i, i))
-		return
+//Check brokers, topic and handlers
+func (kc *KafkaConsumer) Validate() []error {
+	var errs []error
+	if kc.Brokers == "" {
+		errs = append(errs, errors.New("kafka broker can not be empty"))
+	}
+	if kc.Topic == "" {
+		errs = append(errs, errors.New("kafka topic can not be empty"))
+	}
+	IType := reflect.TypeOf(new(IHandler)).Elem()
+	for _, v := range kc.Processor {
+		var name string
+		if i, ok := v.(string); ok {
+			name = i
+		} else if i, ok := v.(map[interface{}]interface{}); ok {
+			name, _ = i[HANDLER_KEY].(string)
+		}
+		if name == "" {
+			errs = append(errs, fmt.Errorf("processor [%v] has no handler", v))
+			continue
+		}
+		b := kc.Ioc.BeansByName(name)
+		if b == nil {
+			errs = append(errs, fmt.Errorf("consumer [%s] is not registed", name))
+			continue
+		}
+		if !b.InstanceType().Implements(IType) {
+			errs = append(errs, fmt.Errorf("consumer [%s] is not impliment of kafka.IHandler", name))
+		}
+	}
+	return errs
+}
+
+//Append handler of message key k, error is returned if handler i can not be created
+func (kc *KafkaConsumer) appendHandler(k string, i string) error {
+	if b := kc.Ioc.BeansByName(i); b != nil && b.PerRequest() {
+		if !b.InstanceType().Implements(reflect.TypeOf(new(IHandler)).Elem()) {
+			ilog.Error(fmt.Sprintf("consumer [%s] is not impliment of kafka.IHandler", i))
+			return nil
+		}
+		kc.handler[k] = append(kc.handler[k], &handlerRef{name: i})
+		return nil
+	}
+
+	h, err := kc.Ioc.InsByNameE(i)
+	if err != nil {
+		return fmt.Errorf("consumer [%s] : %s", i, err)
 	}
 
 	if v, ok := h.(IHandler); ok {
 		if _, ok := kc.handler[k]; !ok {
-			kc.handler[k] = []IHandler{}
+			kc.handler[k] = []*handlerRef{}
 		}
-		kc.handler[k] = append(kc.handler[k], v)
-		return
+		kc.handler[k] = append(kc.handler[k], &handlerRef{name: i, handler: v})
+		return nil
 	}
 
 	ilog.Error(fmt.Sprintf("consumer [%s] is not impliment of kafka.IHandler", i))
+	return nil
 }
 
 func (kc *KafkaConsumer) start () {
@@ -149,7 +210,7 @@ func (kc *KafkaConsumer) start () {
 
 func (kc *KafkaConsumer) deal(msg *sarama.ConsumerMessage) {
 	
-	var handlerList []IHandler
+	var handlerList []*handlerRef
 
 	msgKey := string(msg.Key)
 
@@ -166,7 +227,18 @@ func (kc *KafkaConsumer) deal(msg *sarama.ConsumerMessage) {
 	if len(handlerList) == 0 {
 		ilog.Error(fmt.Sprintf("message key [%s] has not matched handler", msg.Key))
 	} else {
-		for _, h := range handlerList {
+		//Beans of request scope live until the message is dealt
+		ctx, release := kc.Ioc.BeginRequest(kc.Ctx)
+		defer release()
+
+		for _, ref := range handlerList {
+			h := ref.handler
+			if h == nil {
+				if h, _ = kc.Ioc.InsByNameCtx(ctx, ref.name).(IHandler); h == nil {
+					ilog.Error(fmt.Sprintf("consumer [%s] is nil, please check out if [%s] is registed", ref.name, ref.name))
+					continue
+				}
+			}
 			err := h.DealMessage(msg.Topic, msg.Partition, msg.Value)
 			if err == nil {
 				kc.consumer.MarkOffset(msg, "") // mark message as processed
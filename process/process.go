@@ -1,8 +1,8 @@
 package process
 
 type Process struct {
-	Name 			string
-	Class 			string
+	Name 			string	`schema:"required"`
+	Class 			string	`schema:"required"`
 	ExecuteMethod 	string
 	Params 			map[string]interface{}
 }
\ No newline at end of file
@@ -2,10 +2,12 @@ package thrift
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/apache/thrift/lib/go/thrift"
 	"github.com/itea-tgl/itea-go/ilog"
 	"github.com/itea-tgl/itea-go/ioc/iface"
+	"reflect"
 )
 
 type ThriftServer struct {
@@ -19,8 +21,8 @@ type ThriftServer struct {
 	ser 			*thrift.TSimpleServer
 }
 
-//Thrift server start
-func (ts *ThriftServer) Execute() {
+//Thrift server start, error is returned if a processor can not be created
+func (ts *ThriftServer) Execute() error {
 
 	addr := fmt.Sprintf("%s:%d", ts.Ip, ts.Port)
 
@@ -33,54 +35,91 @@ func (ts *ThriftServer) Execute() {
 	transportFactory := thrift.NewTFramedTransportFactory(thrift.NewTTransportFactory())
 	protocolFactory := thrift.NewTBinaryProtocolFactoryDefault()
 
-	ts.ser = thrift.NewTSimpleServer4(ts.processor(), serverTransport, transportFactory, protocolFactory)
+	processor, err := ts.processor()
+	if err != nil {
+		return err
+	}
+	ts.ser = thrift.NewTSimpleServer4(processor, serverTransport, transportFactory, protocolFactory)
 	
 	go ts.stop()
 
 	ilog.Info(fmt.Sprintf("=== 【Thrift】Server [%s] start [%s] ===", ts.Name, addr))
 	if err = ts.ser.Serve(); err != nil {
 		ilog.Error(err)
-		return
 	}
+	return nil
 }
 
 //Thrift processor
-func (ts *ThriftServer) processor() thrift.TProcessor {
+func (ts *ThriftServer) processor() (thrift.TProcessor, error) {
 	if ts.Multiplexed {
 		processor := thrift.NewTMultiplexedProcessor()
 		for _, v := range ts.Processor {
-			if p := ts.check(v.(string)); p != nil {
+			p, err := ts.check(v.(string))
+			if err != nil {
+				return nil, err
+			}
+			if p != nil {
 				processor.RegisterProcessor(p.Name(), p.Processor())
 				ilog.Info(fmt.Sprintf("... 【Thrift】Register processor [%s] multiplexed", p.Name()))
 			}
 		}
-		return processor
+		return processor, nil
 	} else {
 		if ts.Processor != nil && len(ts.Processor) > 0 {
-			if p := ts.check(ts.Processor[0].(string)); p != nil {
+			p, err := ts.check(ts.Processor[0].(string))
+			if err != nil {
+				return nil, err
+			}
+			if p != nil {
 				processor := p.Processor()
 				ilog.Info(fmt.Sprintf("... 【Thrift】Register processor [%s]", p.Name()))
-				return processor
+				return processor, nil
 			}
 		}
 		panic("thrift processor config error")
 	}
 }
 
-func (ts *ThriftServer) check(name string) IProcessor {
-	i := ts.Ioc.InsByName(name)
-	if i == nil {
-		ilog.Error(fmt.Sprintf("processor [%s] is nil, please check out if [%s] is registed", name, name))
-		return nil
+//Get processor of name, error is returned if it can not be created
+func (ts *ThriftServer) check(name string) (IProcessor, error) {
+	i, err := ts.Ioc.InsByNameE(name)
+	if err != nil {
+		return nil, fmt.Errorf("processor [%s] : %s", name, err)
 	}
 	
 	if p, ok := i.(IProcessor); ok {
-		return p
+		return p, nil
 	} else {
 		ilog.Error(fmt.Sprintf("processor [%s] is not impliment of thrift.IProcessor", i))
 	}
 	
-	return nil
+	return nil, nil
+}
+
+//Check processors
+func (ts *ThriftServer) Validate() []error {
+	var errs []error
+	if len(ts.Processor) == 0 {
+		return []error{errors.New("thrift processor config error")}
+	}
+	IType := reflect.TypeOf(new(IProcessor)).Elem()
+	for _, v := range ts.Processor {
+		name, ok := v.(string)
+		if !ok {
+			errs = append(errs, fmt.Errorf("processor [%v] should be a name", v))
+			continue
+		}
+		b := ts.Ioc.BeansByName(name)
+		if b == nil {
+			errs = append(errs, fmt.Errorf("processor [%s] is not registed", name))
+			continue
+		}
+		if !b.InstanceType().Implements(IType) {
+			errs = append(errs, fmt.Errorf("processor [%s] is not impliment of thrift.IProcessor", name))
+		}
+	}
+	return errs
 }
 
 //Thrift server stop
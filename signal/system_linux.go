@@ -57,14 +57,17 @@ func StopProcess() {
 	}
 }
 
-func ProcessSignal(sigs chan os.Signal, s chan bool) {
+//Wait for signals, SIGUSR1 calls reload
+func ProcessSignal(sigs chan os.Signal, s chan bool, reload func()) {
 	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM, syscall.SIGKILL,syscall.SIGUSR1, syscall.SIGUSR2, os.Interrupt)
 	for{
 		msg := <-sigs
 		switch msg {
 		case syscall.SIGUSR1:
-			//reload
 			ilog.Info("[linux] SIGUSR1: ", msg)
+			if reload != nil {
+				reload()
+			}
 			break
 		case syscall.SIGINT, syscall.SIGKILL, syscall.SIGTERM:
 			//logger.Info("application stoping, signal[%v]", msg)
@@ -57,7 +57,8 @@ func StopProcess() {
 	}
 }
 
-func ProcessSignal(sigs chan os.Signal, s chan bool) {
+//Wait for signals, reload is not triggered by signal on windows
+func ProcessSignal(sigs chan os.Signal, s chan bool, reload func()) {
 	signal.Notify(sigs, syscall.SIGINT, syscall.SIGKILL)
 	for{
 		msg := <-sigs
@@ -1,23 +1,25 @@
 package system
 
 import (
+	"errors"
 	"flag"
 	"fmt"
-	"github.com/goinggo/mapstructure"
 	"github.com/itea-tgl/itea-go/constant"
-	"gopkg.in/yaml.v2"
-	"io/ioutil"
+	"github.com/itea-tgl/itea-go/ilog"
 	"os"
 	"path"
 	"reflect"
 	"strings"
 	"sync"
+	"time"
 )
 
 var (
 	Help		bool
 	Start 		bool
 	Stop 		bool
+	Graph 		string	//Format of bean graph to print instead of starting
+	Encrypt 	bool	//Encrypt value read from stdin for config instead of starting
 	Env 		string	//Environment
 	projpath 	string	//Application proj base path
 	Conf		*Config
@@ -28,10 +30,19 @@ func init ()  {
 	flag.BoolVar(&Start, "start", true, "Start application")
 	flag.BoolVar(&Stop, "stop", false, "Stop application")
 	flag.StringVar(&Env, "e", constant.DEFAULT_ENV, "Set application environment")
+	flag.StringVar(&Graph, "graph", "", "Print bean graph as json or dot without starting application")
+	flag.BoolVar(&Encrypt, "encrypt", false, "Print value read from stdin encrypted for config without starting application")
+}
+
+//Parse command line once, by InitConf rather than init so that packages can be tested
+func parseFlags() {
+	if flag.Parsed() {
+		return
+	}
 	flag.Parse()
 	if Help {
 		fmt.Fprintf(os.Stderr, `iteaGo version: iteaGo/%s
-Usage: main [-start|-stop] [-e env]
+Usage: main [-start|-stop] [-e env] [-graph json|dot] [-encrypt < value]
 Options:
 `, constant.ITEAGO_VERSION)
 		flag.PrintDefaults()
@@ -50,41 +61,64 @@ func fileName(p string) string {
 	return strings.TrimSuffix(filenameWithSuffix, fileSuffix)
 }
 
-//Find config
+//Find config, keys are matched exactly first then ignoring case like environment overrides
 func find(k []string, l int, conf map[interface{}]interface{}) interface{} {
 	if l == 1 {
-		return conf[k[0]]
+		return item(conf, k[0])
 	}
-	if c, ok := conf[k[0]];ok {
+	if c, ok := item(conf, k[0]).(map[interface{}]interface{});ok {
 		l--
-		return find(k[1:], l, c.(map[interface{}]interface{}))
+		return find(k[1:], l, c)
 	} else {
 		return nil
 	}
 }
 
+//Item of key in conf
+func item(conf map[interface{}]interface{}, key string) interface{} {
+	if v, ok := conf[key]; ok {
+		return v
+	}
+	for k, v := range conf {
+		if s, ok := k.(string); ok && strings.EqualFold(s, key) {
+			return v
+		}
+	}
+	return nil
+}
+
+//Convert v to a new instance of t like Decode does, pointer of it is returned
 func decode(v interface{}, t reflect.Type) (interface{}, error){
-	ins := reflect.New(t).Interface()
-	if err := mapstructure.Decode(v, ins); err != nil {
+	out, err := Convert(v, t)
+	if err != nil {
 		return nil, err
 	}
-	return ins, nil
+	ins := reflect.New(t)
+	ins.Elem().Set(out)
+	return ins.Interface(), nil
 }
 
 type Config struct {
 	FileName 		string
+	file 			string	//Path of application file
 	config			map[interface{}]interface{}
+	sources 		sources	//Files of keys, kept while loading only
+	listeners 		[]func(keys []string)
 	sl sync.RWMutex
+	rl sync.Mutex	//Serializes reloads
 }
 
 func InitConf(file string) {
+	parseFlags()
 	FileName := fileName(file)
 	Conf = &Config{
 		FileName: FileName,
+		file: file,
 		config: make(map[interface{}]interface{}),
 	}
 	
-	if Stop {
+	//Config is not needed to stop or to encrypt a value
+	if Stop || Encrypt {
 		return
 	}
 	
@@ -93,54 +127,83 @@ func InitConf(file string) {
 	if err != nil {
 		panic(err)
 	}
-	dat, err := ioutil.ReadFile(filePath(file))
+	config, err := load(file)
 	if err != nil {
-		panic("Application config not find")
+		panic(err)
+	}
+	Conf.config = config
+}
+
+//Read application file with its imports and database config into a new config tree,
+//values like ENC(...) are decrypted and the tree should match the schema
+func load(file string) (map[interface{}]interface{}, error) {
+	application, src, err := readConfig(file)
+	if os.IsNotExist(err) {
+		return nil, errors.New("Application config not find")
 	}
-	var application map[interface{}]interface{}
-	err = yaml.Unmarshal(dat, &application)
 	if err != nil {
-		panic("Application config extract error")
+		return nil, fmt.Errorf("Application config extract error : %s", err)
 	}
 
-	Conf.config[FileName] = application
-	
-	ch := make(chan bool)
+	c := &Config{
+		FileName: fileName(file),
+		file: file,
+		config: map[interface{}]interface{}{
+			fileName(file): application,
+		},
+		sources: make(sources),
+	}
+	c.sources.add(fileName(file), src)
+	//Override before reading imports and database config, whose files may be overridden
+	overrideEnv(c.config, c.sources)
+
+	ch := make(chan error)
 	defer close(ch)
-	
+
 	go func() {
-		Conf.importConfig()
-		ch <-true
+		ch <- c.importConfig()
 	}()
 
-	Conf.dbConfig()
-
-	<-ch
+	err = c.dbConfig()
+	if ierr := <-ch; err == nil {
+		err = ierr
+	}
+	if err != nil {
+		return nil, err
+	}
+	overrideEnv(c.config, c.sources)
+	if err := decryptConfig(c.config); err != nil {
+		return nil, err
+	}
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	return c.config, nil
 }
 
 //Extract database config
-func (c *Config) dbConfig() {
+func (c *Config) dbConfig() error {
 	if f := c.GetString(fmt.Sprintf("%s.%s", c.FileName, constant.DATABASE_KEY));!strings.EqualFold(f, "") {
-		dat, err := ioutil.ReadFile(filePath(f))
-		if err != nil {
-			panic("database config not find")
+		databases, src, err := readConfig(f)
+		if os.IsNotExist(err) {
+			return errors.New("database config not find")
 		}
-		var databases map[interface{}]interface{}
-		err = yaml.Unmarshal(dat, &databases)
 		if err != nil {
-			panic(err)
+			return fmt.Errorf("database config extract error : %s", err)
 		}
-		c.sl.RLock()
-		defer c.sl.RUnlock()
+		c.sl.Lock()
+		defer c.sl.Unlock()
 		c.config[constant.DATABASE_KEY] = databases
+		c.sources.add(constant.DATABASE_KEY, src)
 	}
+	return nil
 }
 
 //Extract import config
-func (c *Config) importConfig() {
+func (c *Config) importConfig() error {
 	imp := c.GetArray(fmt.Sprintf("%s.%s", c.FileName, constant.IMPORT_KEY))
 	if len(imp) <= 0 {
-		return
+		return nil
 	}
 	
 	l := len(imp)
@@ -150,92 +213,233 @@ func (c *Config) importConfig() {
 	
 	for _, f := range imp {
 		go func(f string) {
-			dat, err := ioutil.ReadFile(filePath(f))
+			conf, src, err := readConfig(f)
+			if os.IsNotExist(err) {
+				err = fmt.Errorf("import config [%s] not find", f)
+			} else if err != nil {
+				err = fmt.Errorf("import config [%s] extract error : %s", f, err)
+			}
 			if err != nil {
-				ch <- nil
+				ch <- []interface{}{
+					f, err,
+				}
+				return
 			}
-			var conf map[interface{}]interface{}
-			yaml.Unmarshal(dat, &conf)
 			ch <- []interface{}{
-				fileName(f), conf,
+				fileName(f), conf, src,
 			}
-		}(f.(string))
+		}(fmt.Sprint(f))
 	}
 
-	c.sl.RLock()
-	defer c.sl.RUnlock()
+	var err error
+	c.sl.Lock()
+	defer c.sl.Unlock()
 	for i := 0; i < l; i++ {
 		v := <-ch
+		if e, ok := v[1].(error); ok {
+			err = e
+			continue
+		}
 		c.config[v[0].(string)] = v[1]
+		c.sources.add(v[0].(string), v[2].(sources))
 	}
+	return err
 }
 
 func (c *Config) value(key string) interface{} {
 	arr := strings.Split(key, ".")
 	l := len(arr)
+	c.sl.RLock()
+	defer c.sl.RUnlock()
 	return find(arr, l, c.config)
 }
 
-//Get string value
-func (c *Config) GetInt(key string) int {
+//Check if key exists
+func (c *Config) Exists(key string) bool {
+	return c.value(key) != nil
+}
+
+//Decode value of key into out, which should be a pointer.
+//Value is converted like Convert does
+func (c *Config) Decode(key string, out interface{}) error {
+	o := reflect.ValueOf(out)
+	if o.Kind() != reflect.Ptr || o.IsNil() {
+		return fmt.Errorf("decode [%s] : out should be a non-nil pointer", key)
+	}
 	v := c.value(key)
 	if v == nil {
-		return 0
+		return fmt.Errorf("config [%s] %w", key, ErrNotFound)
 	}
-	if s, ok := v.(int); ok {
-		return s
+	ins, err := Convert(v, o.Elem().Type())
+	if err != nil {
+		return fmt.Errorf("decode [%s] : %s", key, err)
 	}
-	return 0
+	o.Elem().Set(ins)
+	return nil
+}
+
+//Get int value, 0 if missing or mistyped
+func (c *Config) GetInt(key string) int {
+	v, _ := c.GetIntE(key)
+	return v
+}
+
+//Get int value, or the error why it can not be got
+func (c *Config) GetIntE(key string) (int, error) {
+	var v int
+	err := c.Decode(key, &v)
+	return v, err
+}
+
+//Get int64 value, 0 if missing or mistyped
+func (c *Config) GetInt64(key string) int64 {
+	v, _ := c.GetInt64E(key)
+	return v
+}
+
+//Get int64 value, or the error why it can not be got
+func (c *Config) GetInt64E(key string) (int64, error) {
+	var v int64
+	err := c.Decode(key, &v)
+	return v, err
+}
+
+//Get float value, 0 if missing or mistyped
+func (c *Config) GetFloat(key string) float64 {
+	v, _ := c.GetFloatE(key)
+	return v
+}
+
+//Get float value, or the error why it can not be got
+func (c *Config) GetFloatE(key string) (float64, error) {
+	var v float64
+	err := c.Decode(key, &v)
+	return v, err
+}
+
+//Get duration value like "5s", or number of seconds. 0 if missing or mistyped
+func (c *Config) GetDuration(key string) time.Duration {
+	v, _ := c.GetDurationE(key)
+	return v
+}
+
+//Get duration value, or the error why it can not be got
+func (c *Config) GetDurationE(key string) (time.Duration, error) {
+	var v time.Duration
+	err := c.Decode(key, &v)
+	return v, err
 }
 
-//Get string value
+//Get string value, empty if missing or mistyped
 func (c *Config) GetString(key string) string {
-	v := c.value(key)
-	if v == nil {
-		return ""
-	}
-	if s, ok := v.(string); ok {
-		return s
-	}
-	return ""
+	v, _ := c.GetStringE(key)
+	return v
 }
 
-//Get boolean value
+//Get string value, or the error why it can not be got
+func (c *Config) GetStringE(key string) (string, error) {
+	var v string
+	err := c.Decode(key, &v)
+	return v, err
+}
+
+//Get boolean value, false if missing or mistyped
 func (c *Config) GetBoolean(key string) bool {
+	v, _ := c.GetBooleanE(key)
+	return v
+}
+
+//Get boolean value, or the error why it can not be got
+func (c *Config) GetBooleanE(key string) (bool, error) {
+	var v bool
+	err := c.Decode(key, &v)
+	return v, err
+}
+
+//Get list of strings from a list or a comma separated string, nil if missing or mistyped
+func (c *Config) GetStringSlice(key string) []string {
+	v, _ := c.GetStringSliceE(key)
+	return v
+}
+
+//Get list of strings, or the error why it can not be got
+func (c *Config) GetStringSliceE(key string) ([]string, error) {
+	var v []string
+	err := c.Decode(key, &v)
+	return v, err
+}
+
+//Get map of string keys, nil if missing or mistyped
+func (c *Config) GetStringMap(key string) map[string]interface{} {
+	v, _ := c.GetStringMapE(key)
+	return v
+}
+
+//Get map of string keys, or the error why it can not be got
+func (c *Config) GetStringMapE(key string) (map[string]interface{}, error) {
+	var v map[string]interface{}
+	err := c.Decode(key, &v)
+	return v, err
+}
+
+//Get value converted to type of def, def if missing or mistyped.
+//Value is returned as it is if def is nil
+func (c *Config) GetOrDefault(key string, def interface{}) interface{} {
 	v := c.value(key)
 	if v == nil {
-		return false
+		return def
 	}
-	if s, ok := v.(bool); ok {
-		return s
+	if def == nil {
+		return v
+	}
+	ins, err := Convert(v, reflect.TypeOf(def))
+	if err != nil {
+		return def
 	}
-	return false
+	return ins.Interface()
 }
 
 //Get config array
 func (c *Config) GetArray(key string) []interface{} {
+	v, _ := c.GetArrayE(key)
+	return v
+}
+
+//Get config array, or the error why it can not be got
+func (c *Config) GetArrayE(key string) ([]interface{}, error) {
 	v := c.value(key)
 	if v == nil {
-		return nil
+		return nil, fmt.Errorf("config [%s] %w", key, ErrNotFound)
 	}
 	if array, ok := v.([]interface{}); ok {
-		return array
+		return array, nil
 	}
-	return nil
+	return nil, fmt.Errorf("config [%s] is %T, not an array", key, v)
 }
 
+//Get struct of type of s, nil if missing
 func (c *Config) GetStruct(key string, s interface{}) interface{} {
+	ins, err := c.GetStructE(key, s)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		ilog.Error(fmt.Sprintf("GetStruct error : %s", err))
+	}
+	return ins
+}
+
+//Get struct of type of s, or the error why it can not be got
+func (c *Config) GetStructE(key string, s interface{}) (interface{}, error) {
 	v := c.value(key)
 	if v == nil {
-		return nil
+		return nil, fmt.Errorf("config [%s] %w", key, ErrNotFound)
 	}
 	ins, err := decode(v, reflect.TypeOf(s))
 	if err != nil {
-		fmt.Println("GetStruct error : ", err)
+		return ins, fmt.Errorf("config [%s] : %s", key, err)
 	}
-	return ins
+	return ins, nil
 }
 
+//Get list of structs of type of s, items failing to decode are skipped
 func (c *Config) GetStructArray(key string, s interface{}) []interface{} {
 	v := c.value(key)
 	if v == nil {
@@ -247,7 +451,7 @@ func (c *Config) GetStructArray(key string, s interface{}) []interface{} {
 		for _, item := range av {
 			ins, err := decode(item, t)
 			if err != nil {
-				fmt.Println("GetStructArray error : ", err)
+				ilog.Error(fmt.Sprintf("GetStructArray error : %s", err))
 				continue
 			}
 			list = append(list, ins)
@@ -257,6 +461,25 @@ func (c *Config) GetStructArray(key string, s interface{}) []interface{} {
 	return nil
 }
 
+//Get list of structs of type of s, or the error of the first item failing to decode
+func (c *Config) GetStructArrayE(key string, s interface{}) ([]interface{}, error) {
+	av, err := c.GetArrayE(key)
+	if err != nil {
+		return nil, err
+	}
+	var list []interface{}
+	t := reflect.TypeOf(s)
+	for i, item := range av {
+		ins, err := decode(item, t)
+		if err != nil {
+			return nil, fmt.Errorf("config [%s.%d] : %s", key, i, err)
+		}
+		list = append(list, ins)
+	}
+	return list, nil
+}
+
+//Get map of structs of type of s, items failing to decode are skipped
 func (c *Config) GetStructMap(key string, s interface{}) map[string]interface{} {
 	v := c.value(key)
 	if v == nil {
@@ -268,16 +491,38 @@ func (c *Config) GetStructMap(key string, s interface{}) map[string]interface{}
 		for k, item := range mv {
 			ins, err := decode(item, t)
 			if err != nil {
-				fmt.Println("GetStructMap error : ", err)
+				ilog.Error(fmt.Sprintf("GetStructMap error : %s", err))
 				continue
 			}
-			m[k.(string)] = ins
+			m[fmt.Sprint(k)] = ins
 		}
 		return m
 	}
 	return nil
 }
 
+//Get map of structs of type of s, or the error of the first item failing to decode
+func (c *Config) GetStructMapE(key string, s interface{}) (map[string]interface{}, error) {
+	v := c.value(key)
+	if v == nil {
+		return nil, fmt.Errorf("config [%s] %w", key, ErrNotFound)
+	}
+	mv, ok := v.(map[interface{}]interface{})
+	if !ok {
+		return nil, fmt.Errorf("config [%s] is %T, not a map", key, v)
+	}
+	m := make(map[string]interface{})
+	t := reflect.TypeOf(s)
+	for k, item := range mv {
+		ins, err := decode(item, t)
+		if err != nil {
+			return nil, fmt.Errorf("config [%s.%v] : %s", key, k, err)
+		}
+		m[fmt.Sprint(k)] = ins
+	}
+	return m, nil
+}
+
 func Int(key string) int {
 	return Conf.GetInt(key)
 }
@@ -300,4 +545,32 @@ func StructArray(key string, s interface{}) []interface{} {
 
 func StructMap(key string, s interface{}) map[string]interface{} {
 	return Conf.GetStructMap(key, s)
-}
\ No newline at end of file
+}
+
+func Int64(key string) int64 {
+	return Conf.GetInt64(key)
+}
+
+func Float(key string) float64 {
+	return Conf.GetFloat(key)
+}
+
+func Duration(key string) time.Duration {
+	return Conf.GetDuration(key)
+}
+
+func Boolean(key string) bool {
+	return Conf.GetBoolean(key)
+}
+
+func StringSlice(key string) []string {
+	return Conf.GetStringSlice(key)
+}
+
+func StringMap(key string) map[string]interface{} {
+	return Conf.GetStringMap(key)
+}
+
+func OrDefault(key string, def interface{}) interface{} {
+	return Conf.GetOrDefault(key, def)
+}
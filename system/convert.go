@@ -0,0 +1,273 @@
+package system
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+//Convert config value v to type t.
+//Numbers are widened or narrowed if they fit, strings are parsed, durations are read from
+//strings like "5s" or from numbers of seconds, comma separated strings fill slices and maps
+//fill structs by `mapstructure`, `yaml` or `json` tag or by field name ignoring case
+func Convert(v interface{}, t reflect.Type) (reflect.Value, error) {
+	out := reflect.New(t).Elem()
+	if err := convert(v, out); err != nil {
+		return reflect.Value{}, err
+	}
+	return out, nil
+}
+
+func convert(v interface{}, out reflect.Value) error {
+	if v == nil {
+		return nil
+	}
+	t := out.Type()
+	vv := reflect.ValueOf(v)
+
+	if t == durationType {
+		d, err := toDuration(v)
+		if err != nil {
+			return err
+		}
+		out.SetInt(int64(d))
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+		if !vv.Type().Implements(t) {
+			return convertError(v, t)
+		}
+		out.Set(vv)
+	case reflect.String:
+		switch vv.Kind() {
+		case reflect.String, reflect.Bool,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			out.SetString(fmt.Sprint(v))
+		default:
+			return convertError(v, t)
+		}
+	case reflect.Bool:
+		switch vv.Kind() {
+		case reflect.Bool:
+			out.SetBool(vv.Bool())
+		case reflect.String:
+			b, err := strconv.ParseBool(strings.TrimSpace(vv.String()))
+			if err != nil {
+				return convertError(v, t)
+			}
+			out.SetBool(b)
+		default:
+			return convertError(v, t)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := toInt64(v)
+		if err != nil || out.OverflowInt(i) {
+			return convertError(v, t)
+		}
+		out.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, err := toInt64(v)
+		if err != nil || i < 0 || out.OverflowUint(uint64(i)) {
+			return convertError(v, t)
+		}
+		out.SetUint(uint64(i))
+	case reflect.Float32, reflect.Float64:
+		f, err := toFloat64(v)
+		if err != nil || out.OverflowFloat(f) {
+			return convertError(v, t)
+		}
+		out.SetFloat(f)
+	case reflect.Slice:
+		return convertSlice(v, out)
+	case reflect.Map:
+		return convertMap(v, out)
+	case reflect.Struct:
+		return convertStruct(v, out)
+	case reflect.Ptr:
+		e := reflect.New(t.Elem())
+		if err := convert(v, e.Elem()); err != nil {
+			return err
+		}
+		out.Set(e)
+	default:
+		if vv.Type().AssignableTo(t) {
+			out.Set(vv)
+			return nil
+		}
+		return convertError(v, t)
+	}
+	return nil
+}
+
+func convertSlice(v interface{}, out reflect.Value) error {
+	vv := reflect.ValueOf(v)
+	if vv.Type().AssignableTo(out.Type()) {
+		out.Set(vv)
+		return nil
+	}
+	if s, ok := v.(string); ok {
+		var items []interface{}
+		for _, item := range strings.Split(s, ",") {
+			if item = strings.TrimSpace(item); !strings.EqualFold(item, "") {
+				items = append(items, item)
+			}
+		}
+		vv = reflect.ValueOf(items)
+	}
+	if vv.Kind() != reflect.Slice && vv.Kind() != reflect.Array {
+		return convertError(v, out.Type())
+	}
+	list := reflect.MakeSlice(out.Type(), vv.Len(), vv.Len())
+	for i := 0; i < vv.Len(); i++ {
+		if err := convert(vv.Index(i).Interface(), list.Index(i)); err != nil {
+			return fieldError(fmt.Sprintf("[%d]", i), err)
+		}
+	}
+	out.Set(list)
+	return nil
+}
+
+func convertMap(v interface{}, out reflect.Value) error {
+	vv := reflect.ValueOf(v)
+	if vv.Kind() != reflect.Map {
+		return convertError(v, out.Type())
+	}
+	t := out.Type()
+	m := reflect.MakeMapWithSize(t, vv.Len())
+	for _, k := range vv.MapKeys() {
+		key := reflect.New(t.Key()).Elem()
+		if err := convert(k.Interface(), key); err != nil {
+			return err
+		}
+		item := reflect.New(t.Elem()).Elem()
+		if err := convert(vv.MapIndex(k).Interface(), item); err != nil {
+			return fieldError(fmt.Sprint(k.Interface()), err)
+		}
+		m.SetMapIndex(key, item)
+	}
+	out.Set(m)
+	return nil
+}
+
+func convertStruct(v interface{}, out reflect.Value) error {
+	vv := reflect.ValueOf(v)
+	if vv.Type() == out.Type() {
+		out.Set(vv)
+		return nil
+	}
+	if vv.Kind() != reflect.Map {
+		return convertError(v, out.Type())
+	}
+	items := make(map[string]interface{})
+	for _, k := range vv.MapKeys() {
+		items[strings.ToLower(fmt.Sprint(k.Interface()))] = vv.MapIndex(k).Interface()
+	}
+	t := out.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !out.Field(i).CanSet() {
+			continue
+		}
+		item, ok := items[strings.ToLower(fieldKey(sf))]
+		if !ok {
+			continue
+		}
+		if err := convert(item, out.Field(i)); err != nil {
+			return fieldError(sf.Name, err)
+		}
+	}
+	return nil
+}
+
+//Key of field in config
+func fieldKey(sf reflect.StructField) string {
+	for _, tag := range []string{"mapstructure", "yaml", "json"} {
+		if name := strings.Split(sf.Tag.Get(tag), ",")[0]; !strings.EqualFold(name, "") && name != "-" {
+			return name
+		}
+	}
+	return sf.Name
+}
+
+func toInt64(v interface{}) (int64, error) {
+	vv := reflect.ValueOf(v)
+	switch vv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return vv.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if vv.Uint() > math.MaxInt64 {
+			return 0, convertError(v, vv.Type())
+		}
+		return int64(vv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		if f := vv.Float(); f == math.Trunc(f) && f >= math.MinInt64 && f <= math.MaxInt64 {
+			return int64(f), nil
+		}
+	case reflect.String:
+		return strconv.ParseInt(strings.TrimSpace(vv.String()), 10, 64)
+	}
+	return 0, fmt.Errorf("%v is not an integer", v)
+}
+
+func toFloat64(v interface{}) (float64, error) {
+	vv := reflect.ValueOf(v)
+	switch vv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(vv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(vv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return vv.Float(), nil
+	case reflect.String:
+		return strconv.ParseFloat(strings.TrimSpace(vv.String()), 64)
+	}
+	return 0, fmt.Errorf("%v is not a number", v)
+}
+
+func toDuration(v interface{}) (time.Duration, error) {
+	if s, ok := v.(string); ok {
+		s = strings.TrimSpace(s)
+		if d, err := time.ParseDuration(s); err == nil {
+			return d, nil
+		}
+		v = s
+	}
+	f, err := toFloat64(v)
+	if err != nil {
+		return 0, convertError(v, durationType)
+	}
+	return time.Duration(f * float64(time.Second)), nil
+}
+
+//Error of converting a field, with the path to it
+type FieldError struct {
+	Path 	string
+	Err 	error
+}
+
+func (fe *FieldError) Error() string {
+	return fmt.Sprintf("%s : %s", fe.Path, fe.Err)
+}
+
+func fieldError(name string, err error) error {
+	if fe, ok := err.(*FieldError); ok {
+		if strings.HasPrefix(fe.Path, "[") {
+			return &FieldError{name + fe.Path, fe.Err}
+		}
+		return &FieldError{name + "." + fe.Path, fe.Err}
+	}
+	return &FieldError{name, err}
+}
+
+func convertError(v interface{}, t reflect.Type) error {
+	return fmt.Errorf("can not convert %v (%T) to %s", v, v, t.String())
+}
@@ -0,0 +1,116 @@
+package system
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type convertDB struct {
+	Host 		string 			`yaml:"host_name"`
+	Port 		int 			`mapstructure:"port_no"`
+	Timeout 	time.Duration
+	Tags 		[]string
+}
+
+type convertApp struct {
+	Name 		string
+	DB 			convertDB 		`yaml:"database"`
+	Replicas 	[]convertDB
+	Ports 		map[string]int
+}
+
+//Config node as decoded
+type tree = map[interface{}]interface{}
+
+func TestConvert(t *testing.T) {
+	cases := []struct {
+		name 		string
+		v 			interface{}
+		want 		interface{}
+	}{
+		{"int to int64", 7, int64(7)},
+		{"int32 to int", int32(5), 5},
+		{"int to int8", 127, int8(127)},
+		{"whole float to int", 3.0, 3},
+		{"string to int", " 42 ", 42},
+		{"int to uint16", 65535, uint16(65535)},
+		{"int to float", 2, 2.0},
+		{"string to float32", "1.5", float32(1.5)},
+		{"number to string", 8080, "8080"},
+		{"string to bool", "true", true},
+		{"duration string", "1m30s", 90 * time.Second},
+		{"duration seconds", 2, 2 * time.Second},
+		{"duration fraction", "1.5", 1500 * time.Millisecond},
+		{"comma separated strings", "a, b ,,c", []string{"a", "b", "c"}},
+		{"comma separated ints", "1,2,3", []int{1, 2, 3}},
+		{"list of numbers", []interface{}{1, "2"}, []int64{1, 2}},
+		{"map", tree{"http": 80, "rpc": "9090"}, map[string]int{"http": 80, "rpc": 9090}},
+		{"pointer", 3, func() *int { i := 3; return &i }()},
+		{"nested struct", tree{
+			"NAME": "app",
+			"database": tree{
+				"host_name": "db",
+				"port_no": "5432",
+				"timeout": "2s",
+				"tags": "main, rw",
+			},
+			"replicas": []interface{}{
+				tree{"HOST_NAME": "replica", "port_no": 5433, "timeout": 1},
+			},
+			"ports": tree{"http": 80},
+			"unknown": true,
+		}, convertApp{
+			Name: "app",
+			DB: convertDB{Host: "db", Port: 5432, Timeout: 2 * time.Second, Tags: []string{"main", "rw"}},
+			Replicas: []convertDB{{Host: "replica", Port: 5433, Timeout: time.Second}},
+			Ports: map[string]int{"http": 80},
+		}},
+	}
+	for _, c := range cases {
+		out, err := Convert(c.v, reflect.TypeOf(c.want))
+		if err != nil {
+			t.Errorf("%s : %s", c.name, err)
+			continue
+		}
+		if got := out.Interface(); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s : got %#v, want %#v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestConvertError(t *testing.T) {
+	cases := []struct {
+		name 		string
+		v 			interface{}
+		t 			reflect.Type
+		err 		string
+	}{
+		{"int overflow", 300, reflect.TypeOf(int8(0)), "can not convert 300 (int) to int8"},
+		{"negative uint", -1, reflect.TypeOf(uint(0)), "can not convert -1 (int) to uint"},
+		{"uint64 over int64", uint64(math.MaxUint64), reflect.TypeOf(int64(0)), "can not convert 18446744073709551615 (uint64) to int64"},
+		{"fraction to int", 3.5, reflect.TypeOf(0), "can not convert 3.5 (float64) to int"},
+		{"float32 overflow", 1e40, reflect.TypeOf(float32(0)), "can not convert 1e+40 (float64) to float32"},
+		{"bad duration", "soon", reflect.TypeOf(time.Duration(0)), "can not convert soon (string) to time.Duration"},
+		{"bad bool", "maybe", reflect.TypeOf(false), "can not convert maybe (string) to bool"},
+		{"slice item", "1,x", reflect.TypeOf([]int{}), "[1] : can not convert x (string) to int"},
+		{"struct field", tree{"database": tree{"port_no": "abc"}}, reflect.TypeOf(convertApp{}),
+			"DB.Port : can not convert abc (string) to int"},
+		{"struct in slice", tree{"replicas": []interface{}{tree{}, tree{"timeout": "soon"}}}, reflect.TypeOf(convertApp{}),
+			"Replicas[1].Timeout : can not convert soon (string) to time.Duration"},
+		{"map item", tree{"ports": tree{"http": "eighty"}}, reflect.TypeOf(convertApp{}),
+			"Ports.http : can not convert eighty (string) to int"},
+		{"struct from scalar", "app", reflect.TypeOf(convertApp{}), "can not convert app (string) to system.convertApp"},
+	}
+	for _, c := range cases {
+		_, err := Convert(c.v, c.t)
+		if err == nil {
+			t.Errorf("%s : no error, want %q", c.name, c.err)
+			continue
+		}
+		if err.Error() != c.err {
+			t.Errorf("%s : got %q, want %q", c.name, err.Error(), c.err)
+		}
+	}
+}
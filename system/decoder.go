@@ -0,0 +1,149 @@
+package system
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"github.com/itea-tgl/itea-go/constant"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"math"
+	"path"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+//Decoder turns content of config file into config tree, maps of the tree are map[interface{}]interface{}
+//and lists are []interface{} like yaml gives
+type Decoder func(data []byte) (map[interface{}]interface{}, error)
+
+var (
+	decoders = map[string]Decoder{
+		".yml": decodeYaml,
+		".yaml": decodeYaml,
+		".json": decodeJson,
+		".toml": decodeToml,
+		".env": decodeEnv,
+	}
+	dl sync.RWMutex
+)
+
+//Register decoder of files with extension ext, like ".ini"
+func RegisterDecoder(ext string, d Decoder) {
+	dl.Lock()
+	defer dl.Unlock()
+	decoders[strings.ToLower(ext)] = d
+}
+
+//Read file and decode it by its extension, yaml is used for unknown extension.
+//Error of reading is returned as is
+func DecodeFile(file string) (map[interface{}]interface{}, error) {
+	dat, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	dl.RLock()
+	d, ok := decoders[strings.ToLower(path.Ext(file))]
+	dl.RUnlock()
+	if !ok {
+		d = decodeYaml
+	}
+	conf, err := d(dat)
+	if err != nil {
+		return nil, fmt.Errorf("%s : %s", path.Base(file), err)
+	}
+	if conf == nil {
+		conf = make(map[interface{}]interface{})
+	}
+	return conf, nil
+}
+
+func decodeYaml(data []byte) (map[interface{}]interface{}, error) {
+	conf := make(map[interface{}]interface{})
+	if err := yaml.Unmarshal(data, &conf); err != nil {
+		return nil, err
+	}
+	return conf, nil
+}
+
+func decodeJson(data []byte) (map[interface{}]interface{}, error) {
+	var conf map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&conf); err != nil {
+		return nil, err
+	}
+	return normalize(conf).(map[interface{}]interface{}), nil
+}
+
+func decodeToml(data []byte) (map[interface{}]interface{}, error) {
+	var conf map[string]interface{}
+	if err := toml.Unmarshal(data, &conf); err != nil {
+		return nil, err
+	}
+	return normalize(conf).(map[interface{}]interface{}), nil
+}
+
+//Decode lines like KEY=value, keys are nested by "__" in lower case like environment overrides.
+//Values are kept as strings
+func decodeEnv(data []byte) (map[interface{}]interface{}, error) {
+	conf := make(map[interface{}]interface{})
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.EqualFold(line, "") || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		i := strings.Index(line, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("line %d : expect KEY=value", n)
+		}
+		key, value := strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+1:])
+		if l := len(value); l >= 2 && (value[0] == '"' || value[0] == '\'') && value[l-1] == value[0] {
+			override(conf, strings.Split(key, constant.ENV_SEPARATOR), value[1:l-1])
+			continue
+		}
+		override(conf, strings.Split(key, constant.ENV_SEPARATOR), value)
+	}
+	return conf, scanner.Err()
+}
+
+//Turn maps into map[interface{}]interface{}, lists into []interface{} and integers into int like yaml does
+func normalize(v interface{}) interface{} {
+	switch t := v.(type) {
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return normalize(i)
+		}
+		f, _ := t.Float64()
+		return f
+	case int64:
+		if t >= math.MinInt && t <= math.MaxInt {
+			return int(t)
+		}
+		return t
+	}
+	vv := reflect.ValueOf(v)
+	switch vv.Kind() {
+	case reflect.Map:
+		m := make(map[interface{}]interface{}, vv.Len())
+		for _, k := range vv.MapKeys() {
+			m[k.Interface()] = normalize(vv.MapIndex(k).Interface())
+		}
+		return m
+	case reflect.Slice:
+		if vv.Type().Elem().Kind() == reflect.Uint8 {
+			return v
+		}
+		list := make([]interface{}, vv.Len())
+		for i := range list {
+			list[i] = normalize(vv.Index(i).Interface())
+		}
+		return list
+	}
+	return v
+}
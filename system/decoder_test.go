@@ -0,0 +1,56 @@
+package system
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+//Files of every format should decode into the tree yaml gives
+func TestDecodersMatchYaml(t *testing.T) {
+	cases := map[string]string{
+		"testdata/app.json": "testdata/app.yml",
+		"testdata/app.toml": "testdata/app.yml",
+		"testdata/app.env": "testdata/env.yml",
+	}
+	for file, yml := range cases {
+		want, err := DecodeFile(yml)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := DecodeFile(file)
+		if err != nil {
+			t.Errorf("%s : %s", file, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s decoded into\n%#v\nwant\n%#v", file, got, want)
+		}
+	}
+}
+
+func TestDecodeNumbers(t *testing.T) {
+	for _, file := range []string{"testdata/app.yml", "testdata/app.json", "testdata/app.toml"} {
+		conf, err := DecodeFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		//Integers beyond float64 precision are kept whole
+		if v, ok := conf["big"].(int); !ok || v != 9007199254740993 {
+			t.Errorf("%s : big is %#v, want int 9007199254740993", file, conf["big"])
+		}
+		if v, ok := conf["port"].(int); !ok || v != 8080 {
+			t.Errorf("%s : port is %#v, want int 8080", file, conf["port"])
+		}
+		if v, ok := conf["ratio"].(float64); !ok || v != 0.5 {
+			t.Errorf("%s : ratio is %#v, want float64 0.5", file, conf["ratio"])
+		}
+	}
+}
+
+func TestDecodeEnvError(t *testing.T) {
+	_, err := decodeEnv([]byte("NAME=app\nBROKEN\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 2 : expect KEY=value") {
+		t.Errorf("got error %v, want error of line 2", err)
+	}
+}
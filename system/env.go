@@ -0,0 +1,88 @@
+package system
+
+import (
+	"github.com/itea-tgl/itea-go/constant"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+//Placeholder like ${VAR} or ${VAR:default}
+var placeholder = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:([^}]*))?\}`)
+
+//Replace placeholders in string values of v with environment variables, v is changed in place.
+//Values stay strings, typed getters and fields convert them when asked
+func interpolate(v interface{}) interface{} {
+	switch t := v.(type) {
+	case string:
+		return expand(t)
+	case map[interface{}]interface{}:
+		for k, item := range t {
+			t[k] = interpolate(item)
+		}
+	case []interface{}:
+		for i, item := range t {
+			t[i] = interpolate(item)
+		}
+	}
+	return v
+}
+
+func expand(s string) string {
+	if !strings.Contains(s, "${") {
+		return s
+	}
+	return placeholder.ReplaceAllStringFunc(s, env)
+}
+
+//Value of placeholder, empty if the variable is not set and there is no default
+func env(ph string) string {
+	m := placeholder.FindStringSubmatch(ph)
+	if v, ok := os.LookupEnv(m[1]); ok {
+		return v
+	}
+	return m[3]
+}
+
+//Override config by environment variables like ITEA_DATABASE__REDIS__HOST for key database.redis.host.
+//Keys are matched ignoring case, missing keys are added in lower case, values are kept as strings
+func overrideEnv(config map[interface{}]interface{}, src sources) {
+	vars := os.Environ()
+	sort.Strings(vars)
+	for _, kv := range vars {
+		i := strings.Index(kv, "=")
+		if i < 0 || !strings.HasPrefix(kv[:i], constant.ENV_PREFIX) {
+			continue
+		}
+		keys := strings.Split(strings.TrimPrefix(kv[:i], constant.ENV_PREFIX), constant.ENV_SEPARATOR)
+		valid := true
+		for _, k := range keys {
+			valid = valid && !strings.EqualFold(k, "")
+		}
+		if valid {
+			override(config, keys, kv[i+1:])
+			src[strings.ToLower(strings.Join(keys, "."))] = "env " + kv[:i]
+		}
+	}
+}
+
+func override(m map[interface{}]interface{}, keys []string, v interface{}) {
+	key := interface{}(strings.ToLower(keys[0]))
+	for k := range m {
+		if s, ok := k.(string); ok && strings.EqualFold(s, keys[0]) {
+			key = k
+			break
+		}
+	}
+	if len(keys) == 1 {
+		m[key] = v
+		return
+	}
+	next, ok := m[key].(map[interface{}]interface{})
+	if !ok {
+		next = make(map[interface{}]interface{})
+		m[key] = next
+	}
+	override(next, keys[1:], v)
+}
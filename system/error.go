@@ -1,5 +1,10 @@
 package system
 
+import "errors"
+
+//Error of key missing in config
+var ErrNotFound = errors.New("not find")
+
 type DatabaseError struct {
 	error string
 }
@@ -1,6 +1,7 @@
 package system
 
 import (
+	"errors"
 	"fmt"
 	"github.com/itea-tgl/itea-go/ilog"
 	"strings"
@@ -18,7 +19,12 @@ type Log struct {
 
 func InitLog() {
 	logtype, logfile, rotate, divide, keep := "", "", false, false, 0
-	if c := Conf.GetStruct(fmt.Sprintf("%s.%s", Conf.FileName, LOG_KEY), Log{}); c != nil {
+	//Logger is not ready yet, so bad log config can not be logged
+	c, err := Conf.GetStructE(fmt.Sprintf("%s.%s", Conf.FileName, LOG_KEY), Log{})
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		panic(err)
+	}
+	if c != nil {
 		logConf := c.(*Log)
 		if !strings.EqualFold(logConf.Type, "") {
 			logtype = logConf.Type
@@ -0,0 +1,102 @@
+package system
+
+import (
+	"fmt"
+	"github.com/itea-tgl/itea-go/constant"
+	"os"
+	"path"
+	"strings"
+)
+
+//Read config file merged with its profiles and interpolated, files are decoded by their extension.
+//Profiles of application.yml are application-{env}.yml then application-local.yml in the same directory,
+//they are optional and later ones win. Maps are merged deeply, other values and lists are replaced.
+//Sources tell the file each key comes from
+func readConfig(file string) (map[interface{}]interface{}, sources, error) {
+	conf, err := DecodeFile(filePath(file))
+	if err != nil {
+		return nil, nil, err
+	}
+	src := make(sources)
+	src.record("", conf, file)
+	for _, p := range profiles(file) {
+		layer, err := DecodeFile(filePath(p))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		src.record("", layer, p)
+		conf = merge(conf, layer)
+	}
+	interpolate(conf)
+	return conf, src, nil
+}
+
+//Sources of config keys, path of key in lower case to the file or environment variable setting it
+type sources map[string]string
+
+//Record src as source of v at prefix and of everything under it
+func (s sources) record(prefix string, v interface{}, src string) {
+	if !strings.EqualFold(prefix, "") {
+		s[strings.ToLower(prefix)] = src
+	}
+	switch t := v.(type) {
+	case map[interface{}]interface{}:
+		for k, item := range t {
+			s.record(join(prefix, fmt.Sprint(k)), item, src)
+		}
+	case []interface{}:
+		for i, item := range t {
+			s.record(join(prefix, fmt.Sprint(i)), item, src)
+		}
+	}
+}
+
+//Add sources of other under prefix
+func (s sources) add(prefix string, other sources) {
+	for k, src := range other {
+		s[join(strings.ToLower(prefix), k)] = src
+	}
+}
+
+//Source of key, or of its nearest parent if key is not recorded
+func (s sources) of(key string) string {
+	key = strings.ToLower(key)
+	for {
+		if src, ok := s[key]; ok {
+			return src
+		}
+		i := strings.LastIndex(key, ".")
+		if i < 0 {
+			return ""
+		}
+		key = key[:i]
+	}
+}
+
+//Profile files of file, in order of merging
+func profiles(file string) []string {
+	ext := path.Ext(file)
+	base := strings.TrimSuffix(file, ext)
+	var list []string
+	if !strings.EqualFold(Env, "") && !strings.EqualFold(Env, constant.LOCAL_PROFILE) {
+		list = append(list, fmt.Sprintf("%s-%s%s", base, Env, ext))
+	}
+	return append(list, fmt.Sprintf("%s-%s%s", base, constant.LOCAL_PROFILE, ext))
+}
+
+//Merge src into dst deeply, values of src win
+func merge(dst, src map[interface{}]interface{}) map[interface{}]interface{} {
+	for k, v := range src {
+		if sm, ok := v.(map[interface{}]interface{}); ok {
+			if dm, ok := dst[k].(map[interface{}]interface{}); ok {
+				dst[k] = merge(dm, sm)
+				continue
+			}
+		}
+		dst[k] = v
+	}
+	return dst
+}
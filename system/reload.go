@@ -0,0 +1,92 @@
+package system
+
+import (
+	"fmt"
+	"github.com/itea-tgl/itea-go/ilog"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+//Read the application file, its imports and database config again and swap them in at once.
+//Config is kept if any file fails, otherwise listeners get the keys changed
+func (c *Config) Reload() error {
+	c.rl.Lock()
+	defer c.rl.Unlock()
+
+	config, err := load(c.file)
+	if err != nil {
+		return fmt.Errorf("reload config fail : %s", err)
+	}
+
+	c.sl.Lock()
+	old := c.config
+	c.config = config
+	listeners := append([]func(keys []string){}, c.listeners...)
+	c.sl.Unlock()
+
+	keys := changedKeys(old, config)
+	ilog.Info(fmt.Sprintf("config reloaded, %d keys changed", len(keys)))
+	if len(keys) > 0 {
+		for _, l := range listeners {
+			l(keys)
+		}
+	}
+	return nil
+}
+
+//Listen to reloads, f gets the keys changed
+func (c *Config) Subscribe(f func(keys []string)) {
+	c.sl.Lock()
+	defer c.sl.Unlock()
+	c.listeners = append(c.listeners, f)
+}
+
+//Whether key is changed, or is a parent or a child of a key changed. Keys are matched ignoring case like lookups
+func Changed(key string, keys []string) bool {
+	key = strings.ToLower(key)
+	for _, k := range keys {
+		k = strings.ToLower(k)
+		if k == key || strings.HasPrefix(k, key + ".") || strings.HasPrefix(key, k + ".") {
+			return true
+		}
+	}
+	return false
+}
+
+//Keys of leaves added, removed or changed from old to new, sorted
+func changedKeys(old, new map[interface{}]interface{}) []string {
+	o, n := make(map[string]interface{}), make(map[string]interface{})
+	flatten("", old, o)
+	flatten("", new, n)
+
+	var keys []string
+	for k, v := range o {
+		if nv, ok := n[k]; !ok || !reflect.DeepEqual(v, nv) {
+			keys = append(keys, k)
+		}
+	}
+	for k := range n {
+		if _, ok := o[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+//Flatten config tree into leaves of dotted keys, lists are leaves
+func flatten(prefix string, v interface{}, out map[string]interface{}) {
+	m, ok := v.(map[interface{}]interface{})
+	if !ok || (len(m) == 0 && !strings.EqualFold(prefix, "")) {
+		out[prefix] = v
+		return
+	}
+	for k, item := range m {
+		key := fmt.Sprint(k)
+		if !strings.EqualFold(prefix, "") {
+			key = prefix + "." + key
+		}
+		flatten(key, item, out)
+	}
+}
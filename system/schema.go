@@ -0,0 +1,412 @@
+package system
+
+import (
+	"fmt"
+	"github.com/itea-tgl/itea-go/constant"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const SCHEMA_TAG = "schema"
+
+//Types of value a rule can ask for
+var schemaTypes = map[string]reflect.Type{
+	"int": reflect.TypeOf(int64(0)),
+	"float": reflect.TypeOf(float64(0)),
+	"string": reflect.TypeOf(""),
+	"bool": reflect.TypeOf(false),
+	"duration": durationType,
+	"array": reflect.TypeOf([]interface{}{}),
+	"map": reflect.TypeOf(map[string]interface{}{}),
+}
+
+var (
+	schemas 	[]*rule
+	schemaMutex sync.Mutex
+)
+
+//Expected shape of the value at Key, `*` in Key matches every item of a map or a list.
+//A required key is only asked for when its parent exists
+type rule struct {
+	Key 		string
+	Type 		string		//One of schemaTypes, empty for any
+	Required 	bool
+	Enum 		[]string
+	Min 		*float64	//Min of number, or min length of string, array and map
+	Max 		*float64	//Max of number, or max length of string, array and map
+	Pattern 	string
+	re 			*regexp.Regexp
+}
+
+//Violation of config schema
+type SchemaError struct {
+	File 		string	//File or environment variable setting the key
+	Key 		string	//Path of key
+	Err 		string
+}
+
+func (se *SchemaError) Error() string {
+	return fmt.Sprintf("config [%s] in [%s] %s", se.Key, se.File, se.Err)
+}
+
+//Violations found at once
+type SchemaErrors []error
+
+func (es SchemaErrors) Error() string {
+	var msg []string
+	for _, e := range es {
+		msg = append(msg, e.Error())
+	}
+	return strings.Join(msg, "\n")
+}
+
+//Declare shape of config at key by fields of struct s, which should be registed before InitConf.
+//Fields are checked by their type and tag `schema`, like `schema:"required,min=1,max=10,enum=a|b,pattern=^\w+$"`,
+//pattern should be the last one. Fields of struct, list of struct and map of struct are declared as well
+func RegisterSchema(key string, s interface{}) {
+	rules, err := structRules(key, reflect.TypeOf(s), nil)
+	if err != nil {
+		panic(fmt.Sprintf("schema of [%s] error : %s", key, err))
+	}
+	schemaMutex.Lock()
+	defer schemaMutex.Unlock()
+	schemas = append(schemas, rules...)
+}
+
+//Rules of fields of struct t at key, visited are the structs declaring it
+func structRules(key string, t reflect.Type, visited []reflect.Type) ([]*rule, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%s is not a struct", t.String())
+	}
+	for _, v := range visited {
+		if v == t {
+			return nil, nil
+		}
+	}
+	visited = append(visited, t)
+
+	var rules []*rule
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get(SCHEMA_TAG)
+		if !strings.EqualFold(sf.PkgPath, "") || tag == "-" {
+			continue
+		}
+		r, err := tagRule(fmt.Sprintf("%s.%s", key, strings.ToLower(fieldKey(sf))), tag)
+		if err != nil {
+			return nil, fmt.Errorf("field %s : %s", sf.Name, err)
+		}
+		r.Type = typeName(sf.Type)
+		rules = append(rules, r)
+
+		ft := sf.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		sub := r.Key
+		if ft.Kind() == reflect.Slice || ft.Kind() == reflect.Map {
+			ft, sub = ft.Elem(), sub + ".*"
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+		}
+		if ft.Kind() != reflect.Struct || ft == durationType {
+			continue
+		}
+		list, err := structRules(sub, ft, visited)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, list...)
+	}
+	return rules, nil
+}
+
+//Rule of key read from tag
+func tagRule(key string, tag string) (*rule, error) {
+	r := &rule{Key: key}
+	for tag != "" {
+		var opt string
+		if strings.HasPrefix(tag, "pattern=") {
+			opt, tag = tag, ""
+		} else if i := strings.Index(tag, ","); i >= 0 {
+			opt, tag = tag[:i], tag[i+1:]
+		} else {
+			opt, tag = tag, ""
+		}
+		name, value := opt, ""
+		if i := strings.Index(opt, "="); i >= 0 {
+			name, value = opt[:i], opt[i+1:]
+		}
+		switch strings.TrimSpace(name) {
+		case "":
+		case "required":
+			r.Required = true
+		case "enum":
+			r.Enum = strings.Split(value, "|")
+		case "min", "max":
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s should be a number", name)
+			}
+			if name == "min" {
+				r.Min = &f
+			} else {
+				r.Max = &f
+			}
+		case "pattern":
+			r.Pattern = value
+		default:
+			return nil, fmt.Errorf("unknown option [%s]", name)
+		}
+	}
+	return r, r.compile()
+}
+
+//Name of schema type of go type t
+func typeName(t reflect.Type) string {
+	if t == durationType {
+		return "duration"
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "int"
+	case reflect.Float32, reflect.Float64:
+		return "float"
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "bool"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "map"
+	case reflect.Ptr:
+		return typeName(t.Elem())
+	}
+	return ""
+}
+
+//Check type and pattern of rule
+func (r *rule) compile() error {
+	if _, ok := schemaTypes[r.Type]; !ok && !strings.EqualFold(r.Type, "") {
+		return fmt.Errorf("unknown type [%s]", r.Type)
+	}
+	if strings.EqualFold(r.Pattern, "") {
+		return nil
+	}
+	re, err := regexp.Compile(r.Pattern)
+	if err != nil {
+		return fmt.Errorf("pattern error : %s", err)
+	}
+	r.re = re
+	return nil
+}
+
+//Read rules from schema file, which maps keys to rules like
+//`database.connections.*.port: {type: int, required: true, min: 1}`
+func schemaFile(file string) ([]*rule, error) {
+	data, err := DecodeFile(filePath(file))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("schema config [%s] not find", file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("schema config [%s] extract error : %s", file, err)
+	}
+	var rules []*rule
+	for k, v := range data {
+		key := fmt.Sprint(k)
+		out, err := Convert(v, reflect.TypeOf(rule{}))
+		if err != nil {
+			return nil, fmt.Errorf("schema config [%s] of [%s] error : %s", file, key, err)
+		}
+		r := out.Interface().(rule)
+		r.Key = key
+		if err := r.compile(); err != nil {
+			return nil, fmt.Errorf("schema config [%s] of [%s] error : %s", file, key, err)
+		}
+		rules = append(rules, &r)
+	}
+	return rules, nil
+}
+
+//Check config of c against registed schemas and schema file of application, all violations are returned at once
+func (c *Config) validate() error {
+	schemaMutex.Lock()
+	rules := append([]*rule{}, schemas...)
+	schemaMutex.Unlock()
+
+	if f := c.GetString(fmt.Sprintf("%s.%s", c.FileName, constant.SCHEMA_KEY)); !strings.EqualFold(f, "") {
+		list, err := schemaFile(f)
+		if err != nil {
+			return err
+		}
+		rules = append(rules, list...)
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+
+	files := c.files()
+	var errs SchemaErrors
+	for _, r := range rules {
+		for _, m := range matchKey("", c.config, strings.Split(r.Key, ".")) {
+			if err := r.check(m.value); !strings.EqualFold(err, "") {
+				errs = append(errs, &SchemaError{
+					File: c.source(files, m.key),
+					Key: m.key,
+					Err: err,
+				})
+			}
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	sort.SliceStable(errs, func(i, j int) bool {
+		return errs[i].(*SchemaError).Key < errs[j].(*SchemaError).Key
+	})
+	return errs
+}
+
+//File or environment variable setting key, the file of its top level key if unknown
+func (c *Config) source(files map[string]string, key string) string {
+	if src := c.sources.of(key); !strings.EqualFold(src, "") {
+		return src
+	}
+	return files[strings.Split(key, ".")[0]]
+}
+
+//Files of top level keys
+func (c *Config) files() map[string]string {
+	files := map[string]string{
+		c.FileName: c.file,
+	}
+	if f := c.GetString(fmt.Sprintf("%s.%s", c.FileName, constant.DATABASE_KEY)); !strings.EqualFold(f, "") {
+		files[constant.DATABASE_KEY] = f
+	}
+	for _, f := range c.GetArray(fmt.Sprintf("%s.%s", c.FileName, constant.IMPORT_KEY)) {
+		files[fileName(fmt.Sprint(f))] = fmt.Sprint(f)
+	}
+	return files
+}
+
+//Violation of v, empty if none
+func (r *rule) check(v interface{}) string {
+	if v == nil {
+		if r.Required {
+			return "is required"
+		}
+		return ""
+	}
+	if t, ok := schemaTypes[r.Type]; ok {
+		if k := t.Kind(); (k == reflect.Slice || k == reflect.Map) && reflect.ValueOf(v).Kind() != k {
+			return fmt.Sprintf("should be %s, not %T", r.Type, v)
+		}
+		if _, err := Convert(v, t); err != nil {
+			return fmt.Sprintf("should be %s : %s", r.Type, err)
+		}
+	}
+	if len(r.Enum) > 0 {
+		found := false
+		for _, e := range r.Enum {
+			if e == fmt.Sprint(v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Sprintf("should be one of [%s]", strings.Join(r.Enum, ", "))
+		}
+	}
+	if r.Min != nil || r.Max != nil {
+		n, what := r.size(v)
+		if r.Min != nil && n < *r.Min {
+			return fmt.Sprintf("%s should be at least %v", what, *r.Min)
+		}
+		if r.Max != nil && n > *r.Max {
+			return fmt.Sprintf("%s should be at most %v", what, *r.Max)
+		}
+	}
+	if r.re != nil && !r.re.MatchString(fmt.Sprint(v)) {
+		return fmt.Sprintf("should match [%s]", r.Pattern)
+	}
+	return ""
+}
+
+//Value of number or duration in seconds compared with min and max, or length of string, list and map
+func (r *rule) size(v interface{}) (float64, string) {
+	switch r.Type {
+	case "int", "float":
+		f, _ := toFloat64(v)
+		return f, "value"
+	case "duration":
+		d, _ := toDuration(v)
+		return d.Seconds(), "value"
+	}
+	switch vv := reflect.ValueOf(v); vv.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		return float64(vv.Len()), "length"
+	}
+	f, _ := toFloat64(v)
+	return f, "value"
+}
+
+type match struct {
+	key 		string
+	value 		interface{}
+}
+
+//Values at keys of tree matching segs, keys of maps are matched ignoring case.
+//Missing value is matched only if its parent exists
+func matchKey(prefix string, node interface{}, segs []string) []match {
+	if len(segs) == 0 {
+		return []match{{prefix, node}}
+	}
+	seg := segs[0]
+	var list []match
+	switch n := node.(type) {
+	case map[interface{}]interface{}:
+		if seg == "*" {
+			keys := make([]string, 0, len(n))
+			items := make(map[string]interface{}, len(n))
+			for k, v := range n {
+				keys = append(keys, fmt.Sprint(k))
+				items[fmt.Sprint(k)] = v
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				list = append(list, matchKey(join(prefix, k), items[k], segs[1:])...)
+			}
+			return list
+		}
+		for k, v := range n {
+			if strings.EqualFold(fmt.Sprint(k), seg) {
+				return matchKey(join(prefix, fmt.Sprint(k)), v, segs[1:])
+			}
+		}
+		if len(segs) == 1 {
+			return []match{{join(prefix, seg), nil}}
+		}
+	case []interface{}:
+		if seg == "*" {
+			for i, v := range n {
+				list = append(list, matchKey(join(prefix, strconv.Itoa(i)), v, segs[1:])...)
+			}
+			return list
+		}
+		if i, err := strconv.Atoi(seg); err == nil && i >= 0 && i < len(n) {
+			return matchKey(join(prefix, seg), n[i], segs[1:])
+		}
+	}
+	return list
+}
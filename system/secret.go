@@ -0,0 +1,171 @@
+package system
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/itea-tgl/itea-go/constant"
+	"github.com/itea-tgl/itea-go/util/algorithm"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+//Prefix of key in base64, keys without it are taken as raw bytes
+const BASE64_KEY_PREFIX = "base64:"
+
+//Encrypted value like ENC(base64) or ENC(id:base64) for key of id
+var encrypted = regexp.MustCompile(`^ENC\((?:([A-Za-z0-9_-]+):)?([A-Za-z0-9+/=]+)\)$`)
+
+//Line of key file with id
+var keyLine = regexp.MustCompile(`^([A-Za-z0-9_-]+)\s*=\s*(\S.*)$`)
+
+//Keys of config by id, empty id for the default key
+type keyring map[string][]byte
+
+//Read keys from key file then environment variables, keys are raw or base64 with prefix base64:,
+//and should be 16, 24 or 32 bytes. Lines of key file are {ID}={KEY}, or base64:{KEY} for the default key, lines starting with # are ignored
+func keys() (keyring, error) {
+	kr := make(keyring)
+	if f := os.Getenv(constant.CONFIG_KEY_FILE_ENV); !strings.EqualFold(f, "") {
+		data, err := ioutil.ReadFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("key file [%s] read error : %s", f, err)
+		}
+		for n, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimSpace(line)
+			if strings.EqualFold(line, "") || strings.HasPrefix(line, "#") {
+				continue
+			}
+			//Only a key in base64 may go without id, a raw one with = would be taken as {ID}={KEY}
+			id, k := "", line
+			if !strings.HasPrefix(line, BASE64_KEY_PREFIX) {
+				m := keyLine.FindStringSubmatch(line)
+				if m == nil {
+					return nil, fmt.Errorf("key file [%s] line %d should be {ID}={KEY}, or base64:{KEY} for the default key", f, n+1)
+				}
+				id, k = m[1], m[2]
+			}
+			if err := kr.add(id, k); err != nil {
+				return nil, fmt.Errorf("key file [%s] : %s", f, err)
+			}
+		}
+	}
+	for _, kv := range os.Environ() {
+		i := strings.Index(kv, "=")
+		if i < 0 || kv[:i] == constant.CONFIG_KEY_ID_ENV || kv[:i] == constant.CONFIG_KEY_FILE_ENV {
+			continue
+		}
+		name := kv[:i]
+		var id string
+		switch {
+		case name == constant.CONFIG_KEY_ENV:
+		case strings.HasPrefix(name, constant.CONFIG_KEY_ENV + "_"):
+			id = strings.TrimPrefix(name, constant.CONFIG_KEY_ENV + "_")
+		default:
+			continue
+		}
+		if err := kr.add(id, kv[i+1:]); err != nil {
+			return nil, fmt.Errorf("environment variable [%s] : %s", name, err)
+		}
+	}
+	return kr, nil
+}
+
+func (kr keyring) add(id string, k string) error {
+	key := []byte(k)
+	if strings.HasPrefix(k, BASE64_KEY_PREFIX) {
+		var err error
+		if key, err = base64.StdEncoding.DecodeString(strings.TrimPrefix(k, BASE64_KEY_PREFIX)); err != nil {
+			return fmt.Errorf("key [%s] is not valid base64 : %s", id, err)
+		}
+	}
+	if l := len(key); l != 16 && l != 24 && l != 32 {
+		return fmt.Errorf("key [%s] should be 16, 24 or 32 bytes, got %d", id, l)
+	}
+	kr[strings.ToLower(id)] = key
+	return nil
+}
+
+//Decrypt value of encrypted string s by key of its id
+func (kr keyring) decrypt(s string) (string, error) {
+	m := encrypted.FindStringSubmatch(s)
+	key, ok := kr[strings.ToLower(m[1])]
+	if !ok {
+		return "", fmt.Errorf("key [%s] not find", m[1])
+	}
+	return algorithm.AesGcmDecrypt(m[2], key)
+}
+
+//Encrypt s by key of id in ITEAGO_CONFIG_KEY_ID, or the default key, into value like ENC(id:base64)
+func EncryptValue(s string) (string, error) {
+	kr, err := keys()
+	if err != nil {
+		return "", err
+	}
+	id := os.Getenv(constant.CONFIG_KEY_ID_ENV)
+	key, ok := kr[strings.ToLower(id)]
+	if !ok {
+		return "", fmt.Errorf("key [%s] not find, set it by %s or %s", id, constant.CONFIG_KEY_ENV, constant.CONFIG_KEY_FILE_ENV)
+	}
+	v, err := algorithm.AesGcmEncrypt(s, key)
+	if err != nil {
+		return "", err
+	}
+	if strings.EqualFold(id, "") {
+		return fmt.Sprintf("ENC(%s)", v), nil
+	}
+	return fmt.Sprintf("ENC(%s:%s)", id, v), nil
+}
+
+//Decrypt encrypted string values of config in place, keys are read only if there is any
+func decryptConfig(config map[interface{}]interface{}) error {
+	var kr keyring
+	var errs []string
+	var walk func(prefix string, v interface{}) interface{}
+	walk = func(prefix string, v interface{}) interface{} {
+		switch t := v.(type) {
+		case string:
+			if !encrypted.MatchString(t) {
+				return v
+			}
+			if kr == nil {
+				var err error
+				if kr, err = keys(); err != nil {
+					errs = append(errs, err.Error())
+					kr = make(keyring)
+				}
+			}
+			plain, err := kr.decrypt(t)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("decrypt config [%s] fail : %s", prefix, err))
+				return v
+			}
+			return plain
+		case map[interface{}]interface{}:
+			for k, item := range t {
+				t[k] = walk(join(prefix, fmt.Sprint(k)), item)
+			}
+		case []interface{}:
+			for i, item := range t {
+				t[i] = walk(join(prefix, fmt.Sprint(i)), item)
+			}
+		}
+		return v
+	}
+	walk("", config)
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return errors.New(strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+func join(prefix string, k string) string {
+	if strings.EqualFold(prefix, "") {
+		return k
+	}
+	return prefix + "." + k
+}
@@ -0,0 +1,167 @@
+package system
+
+import (
+	"bytes"
+	"encoding/base64"
+	"github.com/itea-tgl/itea-go/constant"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	key16 	= "0123456789abcdef"
+	key32 	= "0123456789abcdef0123456789abcdef"
+)
+
+//Remove keys of environment for the test, they are restored when it finishes
+func unsetKeys(t *testing.T) {
+	for _, kv := range os.Environ() {
+		if name := kv[:strings.Index(kv, "=")]; strings.HasPrefix(name, constant.CONFIG_KEY_ENV) {
+			t.Setenv(name, "")
+			os.Unsetenv(name)
+		}
+	}
+}
+
+//Write key file of lines and point the environment to it
+func keyFile(t *testing.T, lines ...string) {
+	f := filepath.Join(t.TempDir(), "keys")
+	if err := os.WriteFile(f, []byte(strings.Join(lines, "\n")), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(constant.CONFIG_KEY_FILE_ENV, f)
+}
+
+func TestKeyFile(t *testing.T) {
+	unsetKeys(t)
+	keyFile(t,
+		"# keys of config",
+		"",
+		"base64:" + base64.StdEncoding.EncodeToString([]byte(key32)),
+		"a = " + key16,
+		"B=abcdefghijklmno=",
+	)
+	kr, err := keys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"": key32,
+		"a": key16,
+		"b": "abcdefghijklmno=",
+	}
+	if len(kr) != len(want) {
+		t.Fatalf("got %d keys, want %d", len(kr), len(want))
+	}
+	for id, k := range want {
+		if !bytes.Equal(kr[id], []byte(k)) {
+			t.Errorf("key [%s] is %q, want %q", id, kr[id], k)
+		}
+	}
+}
+
+func TestKeyFileRejects(t *testing.T) {
+	cases := []struct {
+		line 		string
+		err 		string
+	}{
+		//Raw default key ending with = is not taken as id
+		{"abcdefghijklmno=", "line 1 should be {ID}={KEY}"},
+		{key16, "line 1 should be {ID}={KEY}"},
+		{"base64:!!!", "is not valid base64"},
+		{"a=short", "key [a] should be 16, 24 or 32 bytes, got 5"},
+	}
+	for _, c := range cases {
+		unsetKeys(t)
+		keyFile(t, c.line)
+		if _, err := keys(); err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Errorf("line %q got error %v, want %q", c.line, err, c.err)
+		}
+	}
+}
+
+func TestKeyLength(t *testing.T) {
+	cases := []struct {
+		key 		string
+		size 		int		//0 if the key is rejected
+	}{
+		{key16, 16},
+		{key32[:24], 24},
+		{key32, 32},
+		{"base64:" + base64.StdEncoding.EncodeToString([]byte(key16)), 16},
+		//Base64 without prefix is raw bytes of wrong length
+		{base64.StdEncoding.EncodeToString([]byte(key32)), 0},
+		{"short", 0},
+		{"base64:" + base64.StdEncoding.EncodeToString([]byte("short")), 0},
+	}
+	for _, c := range cases {
+		unsetKeys(t)
+		t.Setenv(constant.CONFIG_KEY_ENV, c.key)
+		kr, err := keys()
+		switch {
+		case c.size == 0 && err == nil:
+			t.Errorf("key %q should be rejected", c.key)
+		case c.size > 0 && err != nil:
+			t.Errorf("key %q : %s", c.key, err)
+		case c.size > 0 && len(kr[""]) != c.size:
+			t.Errorf("key %q is %d bytes, want %d", c.key, len(kr[""]), c.size)
+		}
+	}
+}
+
+func TestEncryptRoundTrip(t *testing.T) {
+	unsetKeys(t)
+	t.Setenv(constant.CONFIG_KEY_ENV, key16)
+	t.Setenv(constant.CONFIG_KEY_ENV + "_DB", key32)
+
+	plain, err := EncryptValue("default secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(constant.CONFIG_KEY_ID_ENV, "DB")
+	named, err := EncryptValue("db secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(named, "ENC(DB:") {
+		t.Fatalf("value %s should name key DB", named)
+	}
+
+	config := map[interface{}]interface{}{
+		"app": map[interface{}]interface{}{
+			"secret": plain,
+			"list": []interface{}{named, "ENC is not encrypted"},
+		},
+	}
+	if err := decryptConfig(config); err != nil {
+		t.Fatal(err)
+	}
+	app := config["app"].(map[interface{}]interface{})
+	if app["secret"] != "default secret" {
+		t.Errorf("secret is %v", app["secret"])
+	}
+	if list := app["list"].([]interface{}); list[0] != "db secret" || list[1] != "ENC is not encrypted" {
+		t.Errorf("list is %v", list)
+	}
+}
+
+func TestDecryptWrongKey(t *testing.T) {
+	unsetKeys(t)
+	t.Setenv(constant.CONFIG_KEY_ENV, key16)
+	v, err := EncryptValue("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv(constant.CONFIG_KEY_ENV, strings.ToUpper(key16))
+	config := map[interface{}]interface{}{"app": map[interface{}]interface{}{"secret": v}}
+	if err := decryptConfig(config); err == nil || !strings.Contains(err.Error(), "decrypt config [app.secret] fail") {
+		t.Errorf("got error %v, want decrypt fail of app.secret", err)
+	}
+	config = map[interface{}]interface{}{"app": "ENC(missing:" + strings.TrimPrefix(v, "ENC(")}
+	if err := decryptConfig(config); err == nil || !strings.Contains(err.Error(), "key [missing] not find") {
+		t.Errorf("got error %v, want missing key", err)
+	}
+}
@@ -0,0 +1,99 @@
+package system
+
+import (
+	"context"
+	"fmt"
+	"github.com/itea-tgl/itea-go/constant"
+	"github.com/itea-tgl/itea-go/ilog"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	WATCH_INTERVAL 	= 2 * time.Second	//Default interval of polling config files
+	WATCH_DEBOUNCE 	= time.Second		//Time files should stay unchanged before reloading
+)
+
+//State of watched file, zero if missing
+type fileStat struct {
+	mod 		time.Time
+	size 		int64
+}
+
+//Interval of watching config files asked by `watch: true` or `watch: 5s` in application file, 0 if not asked
+func (c *Config) WatchInterval() time.Duration {
+	key := fmt.Sprintf("%s.%s", c.FileName, constant.WATCH_KEY)
+	if c.GetBoolean(key) {
+		return WATCH_INTERVAL
+	}
+	d, err := c.GetDurationE(key)
+	if err != nil || d < 0 {
+		return 0
+	}
+	return d
+}
+
+//Poll the application file, its imports, database config, schema and their profiles every interval.
+//Config is reloaded once changed files stay unchanged for a poll and WATCH_DEBOUNCE, so files being
+//written are not read half done. Watching stops when ctx is done
+func (c *Config) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	stats := c.stat()
+	var changed time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if now := c.stat(); !sameStat(stats, now) {
+			stats, changed = now, time.Now()
+			continue
+		}
+		if changed.IsZero() || time.Since(changed) < WATCH_DEBOUNCE {
+			continue
+		}
+		changed = time.Time{}
+		ilog.Info("config files changed, reloading ...")
+		if err := c.Reload(); err != nil {
+			ilog.Error(err)
+		}
+		//Imports may be changed by reloading
+		stats = c.stat()
+	}
+}
+
+//States of files of config
+func (c *Config) stat() map[string]fileStat {
+	files := c.files()
+	if f := c.GetString(fmt.Sprintf("%s.%s", c.FileName, constant.SCHEMA_KEY)); !strings.EqualFold(f, "") {
+		files[constant.SCHEMA_KEY] = f
+	}
+	stats := make(map[string]fileStat)
+	for _, f := range files {
+		for _, p := range append([]string{f}, profiles(f)...) {
+			p = filePath(p)
+			if fi, err := os.Stat(p); err == nil {
+				stats[p] = fileStat{fi.ModTime(), fi.Size()}
+			} else {
+				stats[p] = fileStat{}
+			}
+		}
+	}
+	return stats
+}
+
+func sameStat(a, b map[string]fileStat) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for f, s := range a {
+		if t, ok := b[f]; !ok || !s.mod.Equal(t.mod) || s.size != t.size {
+			return false
+		}
+	}
+	return true
+}